@@ -15,14 +15,23 @@ const (
 )
 
 type AuthConfig struct {
-	Method        string
-	Region        string
-	Endpoint      string
-	AccessKey     string
-	SecretKey     string
-	RoleARN       string
-	WebIdentity   string
-	SkipTLSVerify bool
+	// Target scopes the s3_auth_attempts_total series to the target (or
+	// credential profile) this config belongs to, so two targets sharing an
+	// endpoint don't merge each other's auth attempt counts. Empty in the
+	// single-target (non --config.file) setup.
+	Target          string
+	Method          string
+	Region          string
+	Endpoint        string
+	AccessKey       string
+	SecretKey       string
+	RoleARN         string
+	RoleARNChain    []string
+	RoleSessionName string
+	ExternalID      string
+	DurationSeconds int
+	WebIdentity     string
+	SkipTLSVerify   bool
 }
 
 // DetectAuthMethod determines the authentication method based on available parameters
@@ -36,6 +45,8 @@ func DetectAuthMethod(cfg *AuthConfig) {
 		cfg.Method = AuthMethodWebID
 	case cfg.RoleARN != "":
 		cfg.Method = AuthMethodRole
+	case len(cfg.RoleARNChain) > 0:
+		cfg.Method = AuthMethodRole
 	case cfg.AccessKey != "" && cfg.SecretKey != "":
 		cfg.Method = AuthMethodKeys
 	default:
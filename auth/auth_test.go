@@ -35,6 +35,13 @@ func TestDetectAuthMethod(t *testing.T) {
 			},
 			expectedMethod: AuthMethodKeys,
 		},
+		{
+			name: "Detect Role from chain",
+			config: AuthConfig{
+				RoleARNChain: []string{"arn:aws:iam::123456789012:role/first", "arn:aws:iam::123456789012:role/second"},
+			},
+			expectedMethod: AuthMethodRole,
+		},
 		{
 			name:           "Default to IAM",
 			config:         AuthConfig{},
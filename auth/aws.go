@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
@@ -20,11 +22,45 @@ var (
 	authAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "s3_auth_attempts_total",
 		Help: "Total number of authentication attempts by method and status",
-	}, []string{"method", "status", "s3Endpoint"})
+	}, []string{"method", "status", "s3Endpoint", "s3Target"})
+
+	sdkRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3_sdk_retries_total",
+		Help: "Total number of AWS SDK request retries by endpoint",
+	}, []string{"s3Endpoint"})
 )
 
 func init() {
-	prometheus.MustRegister(authAttempts)
+	prometheus.MustRegister(authAttempts, sdkRetries)
+}
+
+// RegisterMetrics registers the auth attempt/retry counters against reg in
+// addition to the default registerer they're always registered against in
+// init. Multi-target mode serves each target from its own private
+// prometheus.Registry rather than the default one, so without this these
+// metrics would never appear in any target's /metrics response.
+func RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(authAttempts, sdkRetries)
+}
+
+// countingRetryer wraps the standard retryer so every retry delay - which
+// the SDK only computes once per actual retry attempt - is also counted in
+// sdkRetries, giving operators visibility into throttling that would
+// otherwise just show up as scrape latency.
+type countingRetryer struct {
+	aws.Retryer
+	s3Endpoint string
+}
+
+func (r *countingRetryer) RetryDelay(attempt int, err error) (time.Duration, error) {
+	sdkRetries.WithLabelValues(r.s3Endpoint).Inc()
+	return r.Retryer.RetryDelay(attempt, err)
+}
+
+func newCountingRetryer(s3Endpoint string) func() aws.Retryer {
+	return func() aws.Retryer {
+		return &countingRetryer{Retryer: retry.NewStandard(), s3Endpoint: s3Endpoint}
+	}
 }
 
 type AWSAuth struct {
@@ -47,6 +83,13 @@ func (d *defaultConfigLoader) Load(ctx context.Context, optFns ...func(*config.L
 
 var configLoader ConfigLoader = &defaultConfigLoader{}
 
+// newSTSClient builds the STS client used to assume each role in the chain.
+// Overridable in tests so a stub STS server can stand in for the real
+// service without reaching out over the network.
+var newSTSClient = func(cfg aws.Config) stscreds.AssumeRoleAPIClient {
+	return sts.NewFromConfig(cfg)
+}
+
 func (a *AWSAuth) GetConfig(ctx context.Context) (aws.Config, error) {
 	log.Debugf("Starting authentication with method: %s", a.cfg.Method)
 
@@ -56,6 +99,7 @@ func (a *AWSAuth) GetConfig(ctx context.Context) (aws.Config, error) {
 			"method":     a.cfg.Method,
 			"status":     status,
 			"s3Endpoint": a.cfg.Endpoint,
+			"s3Target":   a.cfg.Target,
 		}).Inc()
 	}()
 
@@ -67,6 +111,7 @@ func (a *AWSAuth) GetConfig(ctx context.Context) (aws.Config, error) {
 
 	options := []func(*config.LoadOptions) error{
 		config.WithRegion(a.cfg.Region),
+		config.WithRetryer(newCountingRetryer(a.cfg.Endpoint)),
 	}
 
 	if a.cfg.Endpoint != "" {
@@ -97,14 +142,13 @@ func (a *AWSAuth) GetConfig(ctx context.Context) (aws.Config, error) {
 		))
 
 	case AuthMethodRole:
-		options = append(options, config.WithCredentialsProvider(
-			stscreds.NewAssumeRoleProvider(
-				sts.NewFromConfig(aws.Config{}),
-				a.cfg.RoleARN,
-			),
-		))
+		cfg, err := a.assumeRoleChain(ctx, options)
+		if err != nil {
+			status = "error"
+		}
+		return cfg, err
 
-	case AuthMethodWebID:
+	case AuthMethodWebID, "web_identity": // "web_identity" is the documented -s3_auth_method/S3_AUTH_METHOD value
 		options = append(options, config.WithWebIdentityRoleCredentialOptions(
 			func(o *stscreds.WebIdentityRoleOptions) {
 				o.RoleARN = a.cfg.RoleARN
@@ -125,3 +169,49 @@ func (a *AWSAuth) GetConfig(ctx context.Context) (aws.Config, error) {
 
 	return configLoader.Load(ctx, options...)
 }
+
+// assumeRoleChain loads a base "source profile" aws.Config via configLoader
+// (honoring the region/endpoint/TLS/static-key options already assembled by
+// GetConfig), then walks a.cfg.RoleARNChain (or, if unset, the single
+// a.cfg.RoleARN) in order, assuming each role with the credentials produced
+// by the previous hop. This is the standard cross-account pattern where a
+// hub account's role is assumed first, then used to assume a role in a
+// downstream spoke account.
+func (a *AWSAuth) assumeRoleChain(ctx context.Context, options []func(*config.LoadOptions) error) (aws.Config, error) {
+	roleChain := a.cfg.RoleARNChain
+	if len(roleChain) == 0 {
+		if a.cfg.RoleARN == "" {
+			return aws.Config{}, errors.New("role_arn is required for role authentication")
+		}
+		roleChain = []string{a.cfg.RoleARN}
+	}
+
+	cfg, err := configLoader.Load(ctx, options...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("loading source profile for role chain: %w", err)
+	}
+
+	for _, roleARN := range roleChain {
+		stsClient := newSTSClient(cfg)
+		provider := stscreds.NewAssumeRoleProvider(stsClient, roleARN, func(o *stscreds.AssumeRoleOptions) {
+			if a.cfg.RoleSessionName != "" {
+				o.RoleSessionName = a.cfg.RoleSessionName
+			}
+			if a.cfg.ExternalID != "" {
+				o.ExternalID = aws.String(a.cfg.ExternalID)
+			}
+			if a.cfg.DurationSeconds > 0 {
+				o.Duration = time.Duration(a.cfg.DurationSeconds) * time.Second
+			}
+		})
+
+		cache := aws.NewCredentialsCache(provider)
+		if _, err := cache.Retrieve(ctx); err != nil {
+			return aws.Config{}, fmt.Errorf("assuming role %s: %w", roleARN, err)
+		}
+
+		cfg.Credentials = cache
+	}
+
+	return cfg, nil
+}
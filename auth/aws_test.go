@@ -2,9 +2,13 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetAWSConfigValidation(t *testing.T) {
@@ -58,3 +62,15 @@ func TestGetAWSConfigValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestCountingRetryer_CountsRetryDelay(t *testing.T) {
+	retryer := &countingRetryer{Retryer: retry.NewStandard(), s3Endpoint: "test-endpoint"}
+
+	before := testutil.ToFloat64(sdkRetries.WithLabelValues("test-endpoint"))
+
+	_, err := retryer.RetryDelay(1, errors.New("throttled"))
+	require.NoError(t, err)
+
+	after := testutil.ToFloat64(sdkRetries.WithLabelValues("test-endpoint"))
+	assert.Equal(t, before+1, after)
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubConfigLoader is a fake ConfigLoader that hands back a fixed source
+// profile aws.Config instead of touching real credential providers.
+type stubConfigLoader struct {
+	cfg aws.Config
+	err error
+}
+
+func (s *stubConfigLoader) Load(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+	return s.cfg, s.err
+}
+
+// stubSTSServer records the RoleArn of every AssumeRole call it receives, in
+// order, and returns a success response unless failRoleARN matches, in which
+// case it returns an AccessDenied error for that hop.
+type stubSTSServer struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	calledRoleARNs []string
+}
+
+func newStubSTSServer(t *testing.T, failRoleARN string) *stubSTSServer {
+	t.Helper()
+
+	s := &stubSTSServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		roleARN := r.PostForm.Get("RoleArn")
+
+		s.mu.Lock()
+		s.calledRoleARNs = append(s.calledRoleARNs, roleARN)
+		s.mu.Unlock()
+
+		if roleARN == failRoleARN {
+			w.Header().Set("Content-Type", "text/xml")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<ErrorResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+				<Error>
+					<Type>Sender</Type>
+					<Code>AccessDenied</Code>
+					<Message>not authorized to assume role</Message>
+				</Error>
+				<RequestId>test-request-id</RequestId>
+			</ErrorResponse>`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+			<AssumeRoleResult>
+				<Credentials>
+					<AccessKeyId>ASIAEXAMPLE</AccessKeyId>
+					<SecretAccessKey>secretexample</SecretAccessKey>
+					<SessionToken>tokenexample</SessionToken>
+					<Expiration>2099-01-01T00:00:00Z</Expiration>
+				</Credentials>
+				<AssumedRoleUser>
+					<Arn>%s</Arn>
+					<AssumedRoleId>AROAEXAMPLE:session</AssumedRoleId>
+				</AssumedRoleUser>
+			</AssumeRoleResult>
+			<ResponseMetadata>
+				<RequestId>test-request-id</RequestId>
+			</ResponseMetadata>
+		</AssumeRoleResponse>`, roleARN)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// stubNewSTSClient points every STS client built during a test at server,
+// ignoring the aws.Config passed in since the stub doesn't validate
+// signatures.
+func stubNewSTSClient(server *stubSTSServer) func(aws.Config) stscreds.AssumeRoleAPIClient {
+	return func(aws.Config) stscreds.AssumeRoleAPIClient {
+		return sts.NewFromConfig(aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+		}, func(o *sts.Options) {
+			o.BaseEndpoint = aws.String(server.URL)
+		})
+	}
+}
+
+func withStubbedAuth(t *testing.T, loader ConfigLoader, server *stubSTSServer) {
+	t.Helper()
+
+	origLoader := configLoader
+	origSTSClient := newSTSClient
+	configLoader = loader
+	newSTSClient = stubNewSTSClient(server)
+	t.Cleanup(func() {
+		configLoader = origLoader
+		newSTSClient = origSTSClient
+	})
+}
+
+func TestAssumeRoleChain_SingleRoleARN(t *testing.T) {
+	server := newStubSTSServer(t, "")
+	withStubbedAuth(t, &stubConfigLoader{cfg: aws.Config{Region: "us-east-1"}}, server)
+
+	auth := NewAWSAuth(AuthConfig{
+		Method:  AuthMethodRole,
+		Region:  "us-east-1",
+		RoleARN: "arn:aws:iam::111111111111:role/single",
+	})
+
+	_, err := auth.GetConfig(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arn:aws:iam::111111111111:role/single"}, server.calledRoleARNs)
+}
+
+func TestAssumeRoleChain_WalksChainInOrder(t *testing.T) {
+	server := newStubSTSServer(t, "")
+	withStubbedAuth(t, &stubConfigLoader{cfg: aws.Config{Region: "us-east-1"}}, server)
+
+	auth := NewAWSAuth(AuthConfig{
+		Method: AuthMethodRole,
+		Region: "us-east-1",
+		RoleARNChain: []string{
+			"arn:aws:iam::111111111111:role/hub",
+			"arn:aws:iam::222222222222:role/spoke",
+		},
+	})
+
+	_, err := auth.GetConfig(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"arn:aws:iam::111111111111:role/hub",
+		"arn:aws:iam::222222222222:role/spoke",
+	}, server.calledRoleARNs)
+}
+
+func TestAssumeRoleChain_FailureSurfacesFailingRoleARN(t *testing.T) {
+	failingARN := "arn:aws:iam::222222222222:role/spoke"
+	server := newStubSTSServer(t, failingARN)
+	withStubbedAuth(t, &stubConfigLoader{cfg: aws.Config{Region: "us-east-1"}}, server)
+
+	auth := NewAWSAuth(AuthConfig{
+		Method: AuthMethodRole,
+		Region: "us-east-1",
+		RoleARNChain: []string{
+			"arn:aws:iam::111111111111:role/hub",
+			failingARN,
+		},
+	})
+
+	_, err := auth.GetConfig(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), failingARN)
+	assert.Equal(t, []string{"arn:aws:iam::111111111111:role/hub", failingARN}, server.calledRoleARNs)
+}
+
+func TestAssumeRoleChain_MissingRoleARNErrors(t *testing.T) {
+	auth := NewAWSAuth(AuthConfig{Method: AuthMethodRole, Region: "us-east-1"})
+
+	_, err := auth.GetConfig(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "role_arn is required")
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tropnikovvl/s3-bucket-exporter/storage"
+)
+
+// ConfigFile is the path to an optional multi-target YAML configuration file.
+var ConfigFile string
+
+// TargetAuth mirrors auth.AuthConfig so the config package does not need to
+// import auth (which would create an import cycle once auth grows config
+// helpers of its own).
+type TargetAuth struct {
+	Method          string   `yaml:"method,omitempty"`
+	AccessKey       string   `yaml:"access_key,omitempty"`
+	SecretKey       string   `yaml:"secret_key,omitempty"`
+	RoleARN         string   `yaml:"role_arn,omitempty"`
+	RoleARNChain    []string `yaml:"role_arn_chain,omitempty"`
+	RoleSessionName string   `yaml:"role_session_name,omitempty"`
+	ExternalID      string   `yaml:"external_id,omitempty"`
+	DurationSeconds int      `yaml:"duration_seconds,omitempty"`
+	WebIdentity     string   `yaml:"web_identity,omitempty"`
+	SkipTLSVerify   bool     `yaml:"skip_tls_verify,omitempty"`
+}
+
+// CredentialProfile is a named set of credentials a target can scrape some
+// of its buckets with, in addition to its own top-level Auth. It covers the
+// same authentication methods as TargetAuth so one exporter process can
+// cover buckets owned by several tenant accounts.
+type CredentialProfile struct {
+	TargetAuth `yaml:",inline"`
+	Region     string `yaml:"region,omitempty"`   // defaults to the target's Region when empty
+	Endpoint   string `yaml:"endpoint,omitempty"` // defaults to the target's Endpoint when empty
+}
+
+// TargetInventory points a target at an S3 Inventory report to use instead
+// of a live ListObjectsV2 walk. It only takes effect for buckets listed in
+// the target's bucket_names, since the inventory manifest path is keyed by
+// source bucket name.
+type TargetInventory struct {
+	Bucket string `yaml:"bucket,omitempty"`
+	Prefix string `yaml:"prefix,omitempty"`
+	MaxAge string `yaml:"max_age,omitempty"`
+}
+
+// TargetPosture gates the extra per-bucket API calls needed for security and
+// compliance posture metrics (versioning, encryption, public access block,
+// replication, lifecycle rules, Object Lock, Intelligent-Tiering). Each
+// signal is opt-in since it costs an extra S3 API call per bucket on every
+// scrape.
+type TargetPosture struct {
+	CollectVersioning         bool `yaml:"collect_versioning,omitempty"`
+	CollectEncryption         bool `yaml:"collect_encryption,omitempty"`
+	CollectPublicAccessBlock  bool `yaml:"collect_public_access_block,omitempty"`
+	CollectReplication        bool `yaml:"collect_replication,omitempty"`
+	CollectLifecycleRules     bool `yaml:"collect_lifecycle_rules,omitempty"`
+	CollectObjectLock         bool `yaml:"collect_object_lock,omitempty"`
+	CollectIntelligentTiering bool `yaml:"collect_intelligent_tiering,omitempty"`
+}
+
+// TargetEvents points a target at an SQS queue fed by S3 Event Notifications
+// (ObjectCreated/ObjectRemoved), used to maintain in-memory per-bucket size
+// and count counters instead of a periodic full ListObjectsV2 walk. Like
+// TargetInventory, it only applies to buckets listed in the target's
+// bucket_names, since the queue carries no way to discover bucket names on
+// its own.
+type TargetEvents struct {
+	SQSURL                string `yaml:"sqs_url,omitempty"`
+	Region                string `yaml:"region,omitempty"` // defaults to the target's Region when empty
+	FullReconcileInterval string `yaml:"full_reconcile_interval,omitempty"`
+}
+
+// TargetListing controls the parallel prefix-sharded listing strategy used
+// for buckets too large to finish a single serial ListObjectsV2 pagination
+// within the scrape interval. Workers of 0 or 1 keeps the original serial
+// listing behavior regardless of PrefixShards.
+type TargetListing struct {
+	Workers      int      `yaml:"workers,omitempty"`
+	PrefixShards []string `yaml:"prefix_shards,omitempty"`
+}
+
+// Target describes a single object-storage endpoint to scrape. Backend only
+// accepts "S3" in any letter case (or empty, which defaults to it) today; it exists as the
+// extension point a future non-S3 backend would select itself through,
+// rather than something a config file can turn on yet.
+//
+// Entries in BucketNames may be annotated with a credential profile from
+// CredentialProfiles using "bucketName@profile" (e.g.
+// "tenant-a-bucket@tenant-a"), letting a single target scrape buckets owned
+// by several accounts. Unannotated entries use the target's own Auth.
+//
+// When BucketNames is empty, the target auto-discovers its buckets via
+// ListBuckets on every scrape and narrows the result with
+// BucketIncludeRegex/BucketExcludeRegex/BucketDenyNames/BucketTagSelector.
+// ObjectPrefix scopes object listing to a sub-path of each bucket (explicit
+// or discovered) instead of the whole bucket.
+type Target struct {
+	Name               string                       `yaml:"name"`
+	Endpoint           string                       `yaml:"endpoint"`
+	Region             string                       `yaml:"region"`
+	BucketNames        string                       `yaml:"bucket_names,omitempty"`
+	BucketDenyNames    string                       `yaml:"bucket_deny_names,omitempty"`
+	BucketIncludeRegex string                       `yaml:"bucket_include_regex,omitempty"`
+	BucketExcludeRegex string                       `yaml:"bucket_exclude_regex,omitempty"`
+	BucketTagSelector  string                       `yaml:"bucket_tag_selector,omitempty"` // "key=value"
+	ObjectPrefix       string                       `yaml:"object_prefix,omitempty"`
+	ForcePathStyle     bool                         `yaml:"force_path_style,omitempty"`
+	ScrapeInterval     string                       `yaml:"scrape_interval,omitempty"`
+	Mode               string                       `yaml:"mode,omitempty"` // "list" (default), "inventory" or "events"
+	Inventory          TargetInventory              `yaml:"inventory,omitempty"`
+	Events             TargetEvents                 `yaml:"events,omitempty"`
+	Auth               TargetAuth                   `yaml:"auth,omitempty"`
+	CredentialProfiles map[string]CredentialProfile `yaml:"credential_profiles,omitempty"`
+	Posture            TargetPosture                `yaml:"posture,omitempty"`
+	Listing            TargetListing                `yaml:"listing,omitempty"`
+
+	Backend string `yaml:"backend,omitempty"` // "S3" (default); no other backend is implemented yet
+}
+
+// TargetsFile is the top-level shape of the `--config.file` YAML document.
+type TargetsFile struct {
+	Targets []Target `yaml:"targets"`
+}
+
+// LoadTargetsConfig reads and parses a multi-target configuration file.
+func LoadTargetsConfig(path string) ([]Target, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file %s: %w", path, err)
+	}
+
+	var file TargetsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	seenNames := make(map[string]bool, len(file.Targets))
+	for i, t := range file.Targets {
+		if t.Name == "" {
+			return nil, fmt.Errorf("target at index %d is missing a name", i)
+		}
+		if seenNames[t.Name] {
+			return nil, fmt.Errorf("target %q is defined more than once", t.Name)
+		}
+		seenNames[t.Name] = true
+
+		backend, err := storage.ParseBackend(t.Backend)
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		if backend == storage.BackendS3 && t.Endpoint == "" {
+			return nil, fmt.Errorf("target %q is missing an endpoint", t.Name)
+		}
+		if t.Mode == "inventory" && t.Inventory.Bucket == "" {
+			return nil, fmt.Errorf("target %q has mode \"inventory\" but no inventory.bucket", t.Name)
+		}
+		if t.Mode == "events" && t.Events.SQSURL == "" {
+			return nil, fmt.Errorf("target %q has mode \"events\" but no events.sqs_url", t.Name)
+		}
+	}
+
+	return file.Targets, nil
+}
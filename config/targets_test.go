@@ -0,0 +1,341 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "targets.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestLoadTargetsConfig(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: ceph
+    endpoint: https://ceph.example.com
+    region: us-east-1
+    bucket_names: "bucket1,bucket2"
+    force_path_style: true
+    scrape_interval: 1m
+    auth:
+      method: keys
+      access_key: AKIA...
+      secret_key: secret
+  - name: aws-role
+    endpoint: https://s3.amazonaws.com
+    region: eu-west-1
+    auth:
+      method: role
+      role_arn: arn:aws:iam::123456789012:role/exporter
+`)
+
+	targets, err := LoadTargetsConfig(path)
+
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+
+	assert.Equal(t, "ceph", targets[0].Name)
+	assert.Equal(t, "bucket1,bucket2", targets[0].BucketNames)
+	assert.True(t, targets[0].ForcePathStyle)
+	assert.Equal(t, "1m", targets[0].ScrapeInterval)
+	assert.Equal(t, "keys", targets[0].Auth.Method)
+
+	assert.Equal(t, "aws-role", targets[1].Name)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/exporter", targets[1].Auth.RoleARN)
+}
+
+func TestLoadTargetsConfig_InventoryMode(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: cold-archive
+    endpoint: https://s3.amazonaws.com
+    region: us-east-1
+    bucket_names: "cold-archive"
+    mode: inventory
+    inventory:
+      bucket: inventory-reports
+      prefix: reports
+      max_age: 24h
+`)
+
+	targets, err := LoadTargetsConfig(path)
+
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+
+	assert.Equal(t, "inventory", targets[0].Mode)
+	assert.Equal(t, "inventory-reports", targets[0].Inventory.Bucket)
+	assert.Equal(t, "reports", targets[0].Inventory.Prefix)
+	assert.Equal(t, "24h", targets[0].Inventory.MaxAge)
+}
+
+func TestLoadTargetsConfig_InventoryModeMissingBucket(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: cold-archive
+    endpoint: https://s3.amazonaws.com
+    region: us-east-1
+    mode: inventory
+`)
+
+	_, err := LoadTargetsConfig(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no inventory.bucket")
+}
+
+func TestLoadTargetsConfig_EventsMode(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: live-bucket
+    endpoint: https://s3.amazonaws.com
+    region: us-east-1
+    bucket_names: "live-bucket"
+    mode: events
+    events:
+      sqs_url: https://sqs.us-east-1.amazonaws.com/123456789012/bucket-events
+      region: us-east-1
+      full_reconcile_interval: 12h
+`)
+
+	targets, err := LoadTargetsConfig(path)
+
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+
+	assert.Equal(t, "events", targets[0].Mode)
+	assert.Equal(t, "https://sqs.us-east-1.amazonaws.com/123456789012/bucket-events", targets[0].Events.SQSURL)
+	assert.Equal(t, "us-east-1", targets[0].Events.Region)
+	assert.Equal(t, "12h", targets[0].Events.FullReconcileInterval)
+}
+
+func TestLoadTargetsConfig_EventsModeMissingSQSURL(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: live-bucket
+    endpoint: https://s3.amazonaws.com
+    region: us-east-1
+    bucket_names: "live-bucket"
+    mode: events
+`)
+
+	_, err := LoadTargetsConfig(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no events.sqs_url")
+}
+
+func TestLoadTargetsConfig_ListingFields(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: huge-bucket
+    endpoint: https://s3.amazonaws.com
+    region: us-east-1
+    bucket_names: "huge-bucket"
+    listing:
+      workers: 8
+      prefix_shards:
+        - "2024/"
+        - "2025/"
+`)
+
+	targets, err := LoadTargetsConfig(path)
+
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+
+	assert.Equal(t, 8, targets[0].Listing.Workers)
+	assert.Equal(t, []string{"2024/", "2025/"}, targets[0].Listing.PrefixShards)
+}
+
+func TestLoadTargetsConfig_ExplicitS3Backend(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: s3-archive
+    endpoint: https://s3.example.com
+    backend: s3
+    bucket_names: "s3-archive"
+`)
+
+	targets, err := LoadTargetsConfig(path)
+
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "s3", targets[0].Backend)
+}
+
+func TestLoadTargetsConfig_UnknownBackend(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: ceph
+    endpoint: https://ceph.example.com
+    backend: ceph-rgw
+`)
+
+	_, err := LoadTargetsConfig(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown storage_backend")
+}
+
+func TestLoadTargetsConfig_CredentialProfiles(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: shared
+    endpoint: https://s3.amazonaws.com
+    region: us-east-1
+    bucket_names: "own-bucket,tenant-bucket@tenant-a"
+    auth:
+      method: keys
+      access_key: AKIA...
+      secret_key: secret
+    credential_profiles:
+      tenant-a:
+        method: role
+        role_arn: arn:aws:iam::111111111111:role/exporter
+        role_arn_chain:
+          - arn:aws:iam::222222222222:role/intermediate
+        role_session_name: s3-exporter
+        external_id: tenant-a-ext-id
+        duration_seconds: 1800
+        region: eu-west-1
+`)
+
+	targets, err := LoadTargetsConfig(path)
+
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+
+	profile, ok := targets[0].CredentialProfiles["tenant-a"]
+	require.True(t, ok)
+	assert.Equal(t, "role", profile.Method)
+	assert.Equal(t, "arn:aws:iam::111111111111:role/exporter", profile.RoleARN)
+	assert.Equal(t, []string{"arn:aws:iam::222222222222:role/intermediate"}, profile.RoleARNChain)
+	assert.Equal(t, "s3-exporter", profile.RoleSessionName)
+	assert.Equal(t, "tenant-a-ext-id", profile.ExternalID)
+	assert.Equal(t, 1800, profile.DurationSeconds)
+	assert.Equal(t, "eu-west-1", profile.Region)
+}
+
+func TestLoadTargetsConfig_BucketDiscoveryFields(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: discovered
+    endpoint: https://s3.amazonaws.com
+    region: us-east-1
+    bucket_deny_names: "quarantine,scratch"
+    bucket_include_regex: "^prod-"
+    bucket_exclude_regex: "-tmp$"
+    bucket_tag_selector: "env=prod"
+    object_prefix: "logs/2026/"
+`)
+
+	targets, err := LoadTargetsConfig(path)
+
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+
+	assert.Equal(t, "quarantine,scratch", targets[0].BucketDenyNames)
+	assert.Equal(t, "^prod-", targets[0].BucketIncludeRegex)
+	assert.Equal(t, "-tmp$", targets[0].BucketExcludeRegex)
+	assert.Equal(t, "env=prod", targets[0].BucketTagSelector)
+	assert.Equal(t, "logs/2026/", targets[0].ObjectPrefix)
+}
+
+func TestLoadTargetsConfig_PostureFields(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: audited
+    endpoint: https://s3.amazonaws.com
+    region: us-east-1
+    posture:
+      collect_versioning: true
+      collect_encryption: true
+      collect_public_access_block: true
+      collect_replication: true
+      collect_lifecycle_rules: true
+      collect_object_lock: true
+      collect_intelligent_tiering: true
+`)
+
+	targets, err := LoadTargetsConfig(path)
+
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+
+	assert.True(t, targets[0].Posture.CollectVersioning)
+	assert.True(t, targets[0].Posture.CollectEncryption)
+	assert.True(t, targets[0].Posture.CollectPublicAccessBlock)
+	assert.True(t, targets[0].Posture.CollectReplication)
+	assert.True(t, targets[0].Posture.CollectLifecycleRules)
+	assert.True(t, targets[0].Posture.CollectObjectLock)
+	assert.True(t, targets[0].Posture.CollectIntelligentTiering)
+}
+
+func TestLoadTargetsConfig_MissingName(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - endpoint: https://ceph.example.com
+    region: us-east-1
+`)
+
+	_, err := LoadTargetsConfig(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing a name")
+}
+
+func TestLoadTargetsConfig_DuplicateName(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: ceph
+    endpoint: https://ceph1.example.com
+    region: us-east-1
+  - name: ceph
+    endpoint: https://ceph2.example.com
+    region: us-east-1
+`)
+
+	_, err := LoadTargetsConfig(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "defined more than once")
+}
+
+func TestLoadTargetsConfig_MissingEndpoint(t *testing.T) {
+	path := writeConfigFile(t, `
+targets:
+  - name: ceph
+    region: us-east-1
+`)
+
+	_, err := LoadTargetsConfig(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing an endpoint")
+}
+
+func TestLoadTargetsConfig_FileNotFound(t *testing.T) {
+	_, err := LoadTargetsConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading config file")
+}
+
+func TestLoadTargetsConfig_InvalidYAML(t *testing.T) {
+	path := writeConfigFile(t, "targets: [this is not valid yaml")
+
+	_, err := LoadTargetsConfig(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parsing config file")
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// credentialProfileKey scopes a cached profile client to the target it was
+// configured on, so two targets that happen to reuse the same profile name
+// (e.g. both calling it "tenant-a") never clobber each other's client.
+type credentialProfileKey struct {
+	target  string
+	profile string
+}
+
+// credentialProfileClient bundles a profile's S3 client with the AWSConfig
+// and ForcePathStyle it was built from, so a region override for one of its
+// buckets (see regionClientCache) can be constructed from the profile's own
+// account/endpoint instead of the target's default one.
+type credentialProfileClient struct {
+	client         S3ClientInterface
+	awsConfig      *aws.Config
+	forcePathStyle bool
+}
+
+// credentialProfileClients is a global, mutex-protected cache of per-profile
+// S3 clients, populated once by the target runner for each named profile in
+// config.Target.CredentialProfiles and consulted whenever a bucket name is
+// annotated with "@profile" in bucket_names, so one target can scrape
+// buckets owned by several tenant accounts with a single exporter process.
+var (
+	credentialProfileClientsMutex sync.RWMutex
+	credentialProfileClients      = map[credentialProfileKey]credentialProfileClient{}
+)
+
+// SetCredentialProfileClient registers client to be used for buckets
+// annotated with "@profile" in targetName's bucket_names. awsConfig and
+// forcePathStyle must be the ones client was built from, so region overrides
+// derived from this profile stay within its account/endpoint.
+func SetCredentialProfileClient(targetName, profile string, client S3ClientInterface, awsConfig *aws.Config, forcePathStyle bool) {
+	credentialProfileClientsMutex.Lock()
+	defer credentialProfileClientsMutex.Unlock()
+	credentialProfileClients[credentialProfileKey{targetName, profile}] = credentialProfileClient{
+		client:         client,
+		awsConfig:      awsConfig,
+		forcePathStyle: forcePathStyle,
+	}
+}
+
+// ResetCredentialProfileClients clears all registered profile clients (used by tests).
+func ResetCredentialProfileClients() {
+	credentialProfileClientsMutex.Lock()
+	defer credentialProfileClientsMutex.Unlock()
+	credentialProfileClients = map[credentialProfileKey]credentialProfileClient{}
+}
+
+// clientForProfile returns the client registered for profile on targetName, if any.
+func clientForProfile(targetName, profile string) (credentialProfileClient, bool) {
+	credentialProfileClientsMutex.RLock()
+	defer credentialProfileClientsMutex.RUnlock()
+	pc, ok := credentialProfileClients[credentialProfileKey{targetName, profile}]
+	return pc, ok
+}
+
+// splitBucketProfile splits a "bucketName@profile" bucket_names entry into
+// its bucket name and credential profile. Entries without an "@" return an
+// empty profile, meaning the target's own default credentials apply.
+func splitBucketProfile(raw string) (bucketName, profile string) {
+	if idx := strings.LastIndex(raw, "@"); idx != -1 {
+		return raw[:idx], raw[idx+1:]
+	}
+	return raw, ""
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitBucketProfile(t *testing.T) {
+	tests := []struct {
+		name           string
+		raw            string
+		wantBucketName string
+		wantProfile    string
+	}{
+		{"no profile", "my-bucket", "my-bucket", ""},
+		{"with profile", "my-bucket@tenant-a", "my-bucket", "tenant-a"},
+		{"bucket name containing @", "my@bucket@tenant-a", "my@bucket", "tenant-a"},
+		{"empty", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketName, profile := splitBucketProfile(tt.raw)
+			assert.Equal(t, tt.wantBucketName, bucketName)
+			assert.Equal(t, tt.wantProfile, profile)
+		})
+	}
+}
+
+func TestCredentialProfileClientRegistry(t *testing.T) {
+	defer ResetCredentialProfileClients()
+
+	_, ok := clientForProfile("target-a", "tenant-a")
+	assert.False(t, ok)
+
+	client := &MockS3Client{}
+	awsConfig := &aws.Config{Region: "eu-west-1"}
+	SetCredentialProfileClient("target-a", "tenant-a", client, awsConfig, true)
+
+	got, ok := clientForProfile("target-a", "tenant-a")
+	assert.True(t, ok)
+	assert.Same(t, client, got.client)
+	assert.Same(t, awsConfig, got.awsConfig)
+	assert.True(t, got.forcePathStyle)
+
+	ResetCredentialProfileClients()
+	_, ok = clientForProfile("target-a", "tenant-a")
+	assert.False(t, ok)
+}
+
+func TestCredentialProfileClientRegistry_ScopedPerTarget(t *testing.T) {
+	defer ResetCredentialProfileClients()
+
+	clientA := &MockS3Client{}
+	clientB := &MockS3Client{}
+	SetCredentialProfileClient("target-a", "tenant-a", clientA, &aws.Config{}, false)
+	SetCredentialProfileClient("target-b", "tenant-a", clientB, &aws.Config{}, false)
+
+	gotA, ok := clientForProfile("target-a", "tenant-a")
+	assert.True(t, ok)
+	assert.Same(t, clientA, gotA.client)
+
+	gotB, ok := clientForProfile("target-b", "tenant-a")
+	assert.True(t, ok)
+	assert.Same(t, clientB, gotB.client)
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BucketDiscovery controls which buckets are scraped when no explicit
+// bucket_names list is given, and which sub-path of each selected bucket is
+// scanned for objects. Discovery re-runs on every scrape since fetchBucketData
+// calls ListBuckets fresh each cycle, so newly created buckets are picked up
+// without restarting the exporter.
+type BucketDiscovery struct {
+	// IncludeRegex and ExcludeRegex filter the result of ListBuckets by
+	// bucket name. A bucket must match IncludeRegex (when set) and must not
+	// match ExcludeRegex (when set) to be scraped.
+	IncludeRegex string
+	ExcludeRegex string
+
+	// DenyNames is a comma-separated list of literal bucket names to always
+	// skip, regardless of IncludeRegex/ExcludeRegex.
+	DenyNames string
+
+	// TagSelector restricts discovery to buckets carrying a matching tag,
+	// expressed as "key=value".
+	TagSelector string
+
+	// ObjectPrefix scopes object listing to a sub-path of each bucket
+	// instead of the whole bucket. It applies to explicitly named buckets
+	// too, not just discovered ones.
+	ObjectPrefix string
+}
+
+// filterDiscoveredBuckets applies cfg's include/exclude regex, deny list and
+// tag selector to bucketNames. It is only meant for buckets that came back
+// from a ListBuckets call; an explicit bucket_names list is scraped as-is.
+func filterDiscoveredBuckets(ctx context.Context, client S3ClientInterface, bucketNames []string, cfg BucketDiscovery) ([]string, error) {
+	var includeRe, excludeRe *regexp.Regexp
+	var err error
+
+	if cfg.IncludeRegex != "" {
+		if includeRe, err = regexp.Compile(cfg.IncludeRegex); err != nil {
+			return nil, fmt.Errorf("invalid bucket include regex %q: %w", cfg.IncludeRegex, err)
+		}
+	}
+	if cfg.ExcludeRegex != "" {
+		if excludeRe, err = regexp.Compile(cfg.ExcludeRegex); err != nil {
+			return nil, fmt.Errorf("invalid bucket exclude regex %q: %w", cfg.ExcludeRegex, err)
+		}
+	}
+
+	denyNames := make(map[string]struct{})
+	for _, name := range strings.Split(cfg.DenyNames, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			denyNames[name] = struct{}{}
+		}
+	}
+
+	var tagKey, tagValue string
+	if cfg.TagSelector != "" {
+		tagKey, tagValue, _ = strings.Cut(cfg.TagSelector, "=")
+	}
+
+	filtered := make([]string, 0, len(bucketNames))
+	for _, name := range bucketNames {
+		if _, denied := denyNames[name]; denied {
+			continue
+		}
+		if includeRe != nil && !includeRe.MatchString(name) {
+			continue
+		}
+		if excludeRe != nil && excludeRe.MatchString(name) {
+			continue
+		}
+		if tagKey != "" && fetchAllBucketTags(ctx, client, name)[tagKey] != tagValue {
+			continue
+		}
+		filtered = append(filtered, name)
+	}
+
+	return filtered, nil
+}
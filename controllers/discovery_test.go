@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterDiscoveredBuckets_ExcludeRegex(t *testing.T) {
+	filtered, err := filterDiscoveredBuckets(context.Background(), nil, []string{"prod-logs", "prod-tmp", "prod-archive"}, BucketDiscovery{
+		ExcludeRegex: `-tmp$`,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod-logs", "prod-archive"}, filtered)
+}
+
+func TestFilterDiscoveredBuckets_DenyNames(t *testing.T) {
+	filtered, err := filterDiscoveredBuckets(context.Background(), nil, []string{"a", "b", "c"}, BucketDiscovery{
+		DenyNames: "b, c",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, filtered)
+}
+
+func TestFilterDiscoveredBuckets_InvalidRegex(t *testing.T) {
+	_, err := filterDiscoveredBuckets(context.Background(), nil, []string{"a"}, BucketDiscovery{
+		IncludeRegex: "(",
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid bucket include regex")
+}
+
+func TestFilterDiscoveredBuckets_TagSelector(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketTagging", mock.Anything, mock.MatchedBy(func(in *s3.GetBucketTaggingInput) bool {
+		return aws.ToString(in.Bucket) == "prod-bucket"
+	}), mock.Anything).Return(&s3.GetBucketTaggingOutput{
+		TagSet: []types.Tag{{Key: aws.String("env"), Value: aws.String("prod")}},
+	}, nil)
+	mockClient.On("GetBucketTagging", mock.Anything, mock.MatchedBy(func(in *s3.GetBucketTaggingInput) bool {
+		return aws.ToString(in.Bucket) == "dev-bucket"
+	}), mock.Anything).Return(&s3.GetBucketTaggingOutput{
+		TagSet: []types.Tag{{Key: aws.String("env"), Value: aws.String("dev")}},
+	}, nil)
+
+	filtered, err := filterDiscoveredBuckets(context.Background(), mockClient, []string{"prod-bucket", "dev-bucket"}, BucketDiscovery{
+		TagSelector: "env=prod",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod-bucket"}, filtered)
+}
@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	log "github.com/sirupsen/logrus"
+)
+
+// EventsConfig points a bucket at an SQS queue fed by S3 Event Notifications
+// (ObjectCreated/ObjectRemoved), used to maintain in-memory size/count
+// counters instead of a full ListObjectsV2 walk on every scrape.
+// FullReconcileInterval bounds how long the event-derived counters are
+// trusted before a full walk is forced to correct for any missed or
+// duplicate notifications.
+type EventsConfig struct {
+	SQSURL                string
+	AWSConfig             *aws.Config
+	FullReconcileInterval time.Duration
+}
+
+// SQSClientInterface is the subset of the SQS client events.go needs,
+// mirroring S3ClientInterface so listeners can be unit tested without a live
+// queue.
+type SQSClientInterface interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
+
+// bucketEventState tracks the event-derived counters for a single bucket,
+// along with when it was last reconciled against a full ListObjectsV2 walk.
+type bucketEventState struct {
+	mu            sync.Mutex
+	classes       map[string]StorageClassMetrics
+	lastReconcile time.Time
+}
+
+// eventsKey scopes a registered EventsConfig (and its derived state) to the
+// target it was configured on, so two targets that happen to scrape a
+// same-named bucket never clobber each other's event-derived counters.
+type eventsKey struct {
+	target string
+	bucket string
+}
+
+var (
+	eventsConfigsMutex sync.RWMutex
+	eventsConfigs      = map[eventsKey]EventsConfig{}
+
+	eventsStateMutex sync.Mutex
+	eventsState      = map[eventsKey]*bucketEventState{}
+
+	eventsListenersMutex   sync.Mutex
+	eventsListenersStarted = map[string]struct{}{}
+)
+
+// ConfigureEventsForBuckets registers cfg for every bucket name in
+// bucketNamesCSV, the same comma-separated format accepted by
+// S3_BUCKET_NAMES/bucket_names, scoped to targetName, and starts a single
+// SQS listener goroutine for cfg.SQSURL if one isn't already running for it.
+// All bucket names for a target share the same queue, so one listener routes
+// every message to the right bucket's counters via the event notification's
+// own bucket name instead of one listener per bucket guessing at a
+// hardcoded name. Event-driven mode has no way to discover bucket names on
+// its own, so it only applies to buckets named explicitly.
+func ConfigureEventsForBuckets(ctx context.Context, targetName, bucketNamesCSV string, cfg EventsConfig) {
+	for _, name := range strings.Split(bucketNamesCSV, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		eventsConfigsMutex.Lock()
+		eventsConfigs[eventsKey{targetName, name}] = cfg
+		eventsConfigsMutex.Unlock()
+	}
+
+	startEventListener(ctx, cfg)
+}
+
+// ResetEventsConfigs clears all registered event configs and counters (used by tests).
+func ResetEventsConfigs() {
+	eventsConfigsMutex.Lock()
+	eventsConfigs = map[eventsKey]EventsConfig{}
+	eventsConfigsMutex.Unlock()
+
+	eventsStateMutex.Lock()
+	eventsState = map[eventsKey]*bucketEventState{}
+	eventsStateMutex.Unlock()
+
+	eventsListenersMutex.Lock()
+	eventsListenersStarted = map[string]struct{}{}
+	eventsListenersMutex.Unlock()
+}
+
+// startEventListener launches the SQS poll loop for cfg.SQSURL once, no-op
+// if one is already running for it.
+func startEventListener(ctx context.Context, cfg EventsConfig) {
+	eventsListenersMutex.Lock()
+	if _, started := eventsListenersStarted[cfg.SQSURL]; started {
+		eventsListenersMutex.Unlock()
+		return
+	}
+	eventsListenersStarted[cfg.SQSURL] = struct{}{}
+	eventsListenersMutex.Unlock()
+
+	if cfg.AWSConfig == nil {
+		log.Errorf("SQS queue %s: no AWSConfig configured for event-driven mode, skipping listener", cfg.SQSURL)
+		return
+	}
+
+	client := sqs.NewFromConfig(*cfg.AWSConfig)
+	go runEventListener(ctx, client, cfg.SQSURL)
+}
+
+// eventListenerErrorBackoff bounds how fast runEventListener retries after a
+// ReceiveMessage error. WaitTimeSeconds only throttles successful long-polls,
+// so without this an erroring call (bad queue URL, AccessDenied, expired
+// creds) would return immediately and spin the loop at 100% CPU against SQS.
+const eventListenerErrorBackoff = 5 * time.Second
+
+// runEventListener long-polls queueURL and applies every S3 Event
+// Notification it receives to the counters of whichever bucket the
+// notification names, deleting each message once processed. It runs until
+// ctx is done.
+func runEventListener(ctx context.Context, client SQSClientInterface, queueURL string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		out, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(queueURL),
+			MaxNumberOfMessages: 10,
+			WaitTimeSeconds:     20,
+		})
+		if err != nil {
+			log.Errorf("SQS queue %s: failed to receive messages: %v", queueURL, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(eventListenerErrorBackoff):
+			}
+			continue
+		}
+
+		for _, msg := range out.Messages {
+			applyS3EventNotification(queueURL, aws.ToString(msg.Body))
+
+			if _, err := client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(queueURL),
+				ReceiptHandle: msg.ReceiptHandle,
+			}); err != nil {
+				log.Errorf("SQS queue %s: failed to delete processed message: %v", queueURL, err)
+			}
+		}
+	}
+}
+
+// s3EventNotification is the subset of the S3 Event Notification schema
+// events.go needs. See:
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/notification-content-structure.html
+type s3EventNotification struct {
+	Records []struct {
+		EventName string `json:"eventName"`
+		S3        struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Size float64 `json:"size"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+}
+
+// applyS3EventNotification parses an S3 Event Notification message body
+// received from queueURL and applies each record to the counters of
+// whichever target registered the record's own bucket name
+// (record.S3.Bucket.Name) against that queue, since one queue can carry
+// events for every bucket sharing a target's events config. Records for
+// buckets with no registered EventsConfig on queueURL are skipped, as are
+// malformed bodies, rather than failing the listener. S3 event notifications
+// don't carry the object's storage class, so, like calculateBucketMetrics,
+// objects with no known class are counted as STANDARD.
+func applyS3EventNotification(queueURL, body string) {
+	var notification s3EventNotification
+	if err := json.Unmarshal([]byte(body), &notification); err != nil {
+		log.Errorf("SQS queue %s: failed to parse S3 event notification: %v", queueURL, err)
+		return
+	}
+
+	for _, record := range notification.Records {
+		bucketName := record.S3.Bucket.Name
+		if bucketName == "" {
+			continue
+		}
+
+		key, configured := eventsKeyForQueueAndBucket(queueURL, bucketName)
+		if !configured {
+			continue
+		}
+
+		state := eventStateFor(key)
+		state.mu.Lock()
+		metrics := state.classes["STANDARD"]
+		switch {
+		case strings.HasPrefix(record.EventName, "ObjectCreated:"):
+			metrics.Size += record.S3.Object.Size
+			metrics.ObjectNumber++
+		case strings.HasPrefix(record.EventName, "ObjectRemoved:"):
+			metrics.Size -= record.S3.Object.Size
+			metrics.ObjectNumber--
+		default:
+			state.mu.Unlock()
+			continue
+		}
+		state.classes["STANDARD"] = metrics
+		state.mu.Unlock()
+	}
+}
+
+// eventsKeyForQueueAndBucket finds the registered eventsKey whose bucket
+// matches bucketName and whose EventsConfig points at queueURL - the
+// notification itself carries no target identifier, so the queue it arrived
+// on (combined with the bucket name) is the only way to recover which
+// target's counters it belongs to.
+func eventsKeyForQueueAndBucket(queueURL, bucketName string) (eventsKey, bool) {
+	eventsConfigsMutex.RLock()
+	defer eventsConfigsMutex.RUnlock()
+
+	for key, cfg := range eventsConfigs {
+		if key.bucket == bucketName && cfg.SQSURL == queueURL {
+			return key, true
+		}
+	}
+	return eventsKey{}, false
+}
+
+// eventStateFor returns key's counter state, creating it if this is the
+// first event or reconcile seen for it.
+func eventStateFor(key eventsKey) *bucketEventState {
+	eventsStateMutex.Lock()
+	defer eventsStateMutex.Unlock()
+
+	state, ok := eventsState[key]
+	if !ok {
+		state = &bucketEventState{classes: make(map[string]StorageClassMetrics)}
+		eventsState[key] = state
+	}
+	return state
+}
+
+// reconcileEventCounters replaces targetName/bucketName's event-derived
+// counters with a fresh full-scan result and resets its reconcile clock,
+// correcting for any notifications the queue missed or double-delivered.
+// classes is copied rather than stored by reference: the caller hands the
+// same map to Bucket.StorageClasses for the collector to read, and the SQS
+// listener goroutine mutates state.classes concurrently with scrapes, so
+// sharing the map would let Collect's range race the listener's writes.
+func reconcileEventCounters(targetName, bucketName string, classes map[string]StorageClassMetrics) {
+	state := eventStateFor(eventsKey{targetName, bucketName})
+
+	owned := make(map[string]StorageClassMetrics, len(classes))
+	for k, v := range classes {
+		owned[k] = v
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.classes = owned
+	state.lastReconcile = time.Now()
+}
+
+// reconcileIfEventsConfigured resets targetName/bucketName's event counters
+// to the result of a just-completed full scan when an EventsConfig is
+// registered for it, so every live listing (whether triggered by a
+// reconcile window expiring or by events not being fresh yet) keeps the
+// counters in sync.
+func reconcileIfEventsConfigured(targetName, bucketName string, classes map[string]StorageClassMetrics) {
+	eventsConfigsMutex.RLock()
+	_, configured := eventsConfigs[eventsKey{targetName, bucketName}]
+	eventsConfigsMutex.RUnlock()
+	if !configured {
+		return
+	}
+
+	reconcileEventCounters(targetName, bucketName, classes)
+}
+
+// eventCountersIfFresh returns targetName/bucketName's event-derived
+// counters when an EventsConfig is registered for it and its last full
+// reconcile is within cfg.FullReconcileInterval, so fetchBucketData can skip
+// the live listing entirely between reconciles.
+func eventCountersIfFresh(targetName, bucketName string) (map[string]StorageClassMetrics, bool) {
+	key := eventsKey{targetName, bucketName}
+
+	eventsConfigsMutex.RLock()
+	cfg, configured := eventsConfigs[key]
+	eventsConfigsMutex.RUnlock()
+	if !configured {
+		return nil, false
+	}
+
+	eventsStateMutex.Lock()
+	state, ok := eventsState[key]
+	eventsStateMutex.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.lastReconcile.IsZero() || time.Since(state.lastReconcile) >= cfg.FullReconcileInterval {
+		return nil, false
+	}
+
+	classes := make(map[string]StorageClassMetrics, len(state.classes))
+	for k, v := range state.classes {
+		classes[k] = v
+	}
+	return classes, true
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testQueueURL = "https://sqs.example.com/queue"
+
+func TestApplyS3EventNotification_ObjectCreatedAndRemoved(t *testing.T) {
+	ResetEventsConfigs()
+	defer ResetEventsConfigs()
+
+	eventsConfigsMutex.Lock()
+	eventsConfigs[eventsKey{"target-a", "bucket1"}] = EventsConfig{SQSURL: testQueueURL}
+	eventsConfigsMutex.Unlock()
+
+	applyS3EventNotification(testQueueURL, `{
+		"Records": [
+			{"eventName": "ObjectCreated:Put", "s3": {"bucket": {"name": "bucket1"}, "object": {"size": 1024}}},
+			{"eventName": "ObjectCreated:Put", "s3": {"bucket": {"name": "bucket1"}, "object": {"size": 2048}}}
+		]
+	}`)
+	applyS3EventNotification(testQueueURL, `{
+		"Records": [
+			{"eventName": "ObjectRemoved:Delete", "s3": {"bucket": {"name": "bucket1"}, "object": {"size": 1024}}}
+		]
+	}`)
+
+	state := eventStateFor(eventsKey{"target-a", "bucket1"})
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	assert.Equal(t, float64(2048), state.classes["STANDARD"].Size)
+	assert.Equal(t, float64(1), state.classes["STANDARD"].ObjectNumber)
+}
+
+func TestApplyS3EventNotification_MalformedBodyIsIgnored(t *testing.T) {
+	ResetEventsConfigs()
+	defer ResetEventsConfigs()
+
+	applyS3EventNotification(testQueueURL, "not json")
+
+	state := eventStateFor(eventsKey{"target-a", "bucket1"})
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	assert.Empty(t, state.classes)
+}
+
+func TestApplyS3EventNotification_RoutesRecordsToTheirOwnBucket(t *testing.T) {
+	ResetEventsConfigs()
+	defer ResetEventsConfigs()
+
+	eventsConfigsMutex.Lock()
+	eventsConfigs[eventsKey{"target-a", "bucket1"}] = EventsConfig{SQSURL: testQueueURL}
+	eventsConfigs[eventsKey{"target-a", "bucket2"}] = EventsConfig{SQSURL: testQueueURL}
+	eventsConfigsMutex.Unlock()
+
+	applyS3EventNotification(testQueueURL, `{
+		"Records": [
+			{"eventName": "ObjectCreated:Put", "s3": {"bucket": {"name": "bucket1"}, "object": {"size": 1024}}},
+			{"eventName": "ObjectCreated:Put", "s3": {"bucket": {"name": "bucket2"}, "object": {"size": 4096}}},
+			{"eventName": "ObjectCreated:Put", "s3": {"bucket": {"name": "bucket2"}, "object": {"size": 4096}}}
+		]
+	}`)
+
+	bucket1 := eventStateFor(eventsKey{"target-a", "bucket1"})
+	bucket1.mu.Lock()
+	assert.Equal(t, float64(1024), bucket1.classes["STANDARD"].Size)
+	assert.Equal(t, float64(1), bucket1.classes["STANDARD"].ObjectNumber)
+	bucket1.mu.Unlock()
+
+	bucket2 := eventStateFor(eventsKey{"target-a", "bucket2"})
+	bucket2.mu.Lock()
+	assert.Equal(t, float64(2*4096), bucket2.classes["STANDARD"].Size)
+	assert.Equal(t, float64(2), bucket2.classes["STANDARD"].ObjectNumber)
+	bucket2.mu.Unlock()
+}
+
+func TestApplyS3EventNotification_RoutesByQueueWhenTargetsShareABucketName(t *testing.T) {
+	ResetEventsConfigs()
+	defer ResetEventsConfigs()
+
+	const otherQueueURL = "https://sqs.example.com/other-queue"
+
+	eventsConfigsMutex.Lock()
+	eventsConfigs[eventsKey{"target-a", "shared-bucket"}] = EventsConfig{SQSURL: testQueueURL}
+	eventsConfigs[eventsKey{"target-b", "shared-bucket"}] = EventsConfig{SQSURL: otherQueueURL}
+	eventsConfigsMutex.Unlock()
+
+	applyS3EventNotification(testQueueURL, `{
+		"Records": [
+			{"eventName": "ObjectCreated:Put", "s3": {"bucket": {"name": "shared-bucket"}, "object": {"size": 1024}}}
+		]
+	}`)
+	applyS3EventNotification(otherQueueURL, `{
+		"Records": [
+			{"eventName": "ObjectCreated:Put", "s3": {"bucket": {"name": "shared-bucket"}, "object": {"size": 4096}}}
+		]
+	}`)
+
+	targetA := eventStateFor(eventsKey{"target-a", "shared-bucket"})
+	targetA.mu.Lock()
+	assert.Equal(t, float64(1024), targetA.classes["STANDARD"].Size)
+	targetA.mu.Unlock()
+
+	targetB := eventStateFor(eventsKey{"target-b", "shared-bucket"})
+	targetB.mu.Lock()
+	assert.Equal(t, float64(4096), targetB.classes["STANDARD"].Size)
+	targetB.mu.Unlock()
+}
+
+func TestApplyS3EventNotification_UnregisteredBucketIsIgnored(t *testing.T) {
+	ResetEventsConfigs()
+	defer ResetEventsConfigs()
+
+	applyS3EventNotification(testQueueURL, `{
+		"Records": [
+			{"eventName": "ObjectCreated:Put", "s3": {"bucket": {"name": "unregistered-bucket"}, "object": {"size": 1024}}}
+		]
+	}`)
+
+	eventsStateMutex.Lock()
+	_, tracked := eventsState[eventsKey{"target-a", "unregistered-bucket"}]
+	eventsStateMutex.Unlock()
+	assert.False(t, tracked, "events for buckets with no registered EventsConfig should not be tracked")
+}
+
+func TestEventCountersIfFresh_RequiresConfigAndRecentReconcile(t *testing.T) {
+	ResetEventsConfigs()
+	defer ResetEventsConfigs()
+
+	_, fresh := eventCountersIfFresh("target-a", "bucket1")
+	assert.False(t, fresh, "no EventsConfig registered yet")
+
+	eventsConfigsMutex.Lock()
+	eventsConfigs[eventsKey{"target-a", "bucket1"}] = EventsConfig{SQSURL: testQueueURL, FullReconcileInterval: time.Hour}
+	eventsConfigsMutex.Unlock()
+
+	_, fresh = eventCountersIfFresh("target-a", "bucket1")
+	assert.False(t, fresh, "no reconcile has happened yet")
+
+	reconcileEventCounters("target-a", "bucket1", map[string]StorageClassMetrics{"STANDARD": {Size: 512, ObjectNumber: 1}})
+
+	classes, fresh := eventCountersIfFresh("target-a", "bucket1")
+	require.True(t, fresh)
+	assert.Equal(t, float64(512), classes["STANDARD"].Size)
+}
+
+func TestEventCountersIfFresh_ExpiresAfterReconcileInterval(t *testing.T) {
+	ResetEventsConfigs()
+	defer ResetEventsConfigs()
+
+	eventsConfigsMutex.Lock()
+	eventsConfigs[eventsKey{"target-a", "bucket1"}] = EventsConfig{SQSURL: testQueueURL, FullReconcileInterval: time.Nanosecond}
+	eventsConfigsMutex.Unlock()
+
+	reconcileEventCounters("target-a", "bucket1", map[string]StorageClassMetrics{"STANDARD": {Size: 512, ObjectNumber: 1}})
+	time.Sleep(time.Millisecond)
+
+	_, fresh := eventCountersIfFresh("target-a", "bucket1")
+	assert.False(t, fresh, "counters older than FullReconcileInterval should not be trusted")
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Default bucket boundaries chosen around common lifecycle-policy thresholds
+// (30/90/180/365/730 days) and typical object sizes (1KiB up to 2GiB).
+var (
+	DefaultObjectAgeBuckets  = []float64{30 * 86400, 90 * 86400, 180 * 86400, 365 * 86400, 730 * 86400}
+	DefaultObjectSizeBuckets = prometheus.ExponentialBuckets(1024, 8, 8)
+)
+
+var (
+	histogramsMutex sync.RWMutex
+
+	objectAgeSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "s3_bucket_object_age_seconds",
+		Help:    "Distribution of object age (time since last modified) per bucket and storage class",
+		Buckets: DefaultObjectAgeBuckets,
+	}, []string{"s3Target", "bucketName", "storageClass"})
+
+	objectSizeBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "s3_bucket_object_size_bytes",
+		Help:    "Distribution of object size per bucket and storage class",
+		Buckets: DefaultObjectSizeBuckets,
+	}, []string{"s3Target", "bucketName", "storageClass"})
+)
+
+func init() {
+	prometheus.MustRegister(objectAgeSeconds, objectSizeBytes)
+}
+
+// RegisterHistogramMetrics registers the object age/size histograms against
+// reg in addition to the default registerer they're always registered
+// against in init. Multi-target mode serves each target from its own
+// private prometheus.Registry rather than the default one, so without this
+// these metrics would never appear in any target's /metrics response. Must
+// be called after SetHistogramBuckets if the bucket boundaries are being
+// overridden, since that swaps in new HistogramVec instances.
+func RegisterHistogramMetrics(reg prometheus.Registerer) {
+	histogramsMutex.RLock()
+	defer histogramsMutex.RUnlock()
+
+	reg.MustRegister(objectAgeSeconds, objectSizeBytes)
+}
+
+// SetHistogramBuckets overrides the default age/size bucket boundaries. It
+// must be called before the first scrape, since prometheus HistogramVecs
+// fix their boundaries at construction time.
+func SetHistogramBuckets(ageBuckets, sizeBuckets []float64) {
+	histogramsMutex.Lock()
+	defer histogramsMutex.Unlock()
+
+	prometheus.Unregister(objectAgeSeconds)
+	prometheus.Unregister(objectSizeBytes)
+
+	objectAgeSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "s3_bucket_object_age_seconds",
+		Help:    "Distribution of object age (time since last modified) per bucket and storage class",
+		Buckets: ageBuckets,
+	}, []string{"s3Target", "bucketName", "storageClass"})
+
+	objectSizeBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "s3_bucket_object_size_bytes",
+		Help:    "Distribution of object size per bucket and storage class",
+		Buckets: sizeBuckets,
+	}, []string{"s3Target", "bucketName", "storageClass"})
+
+	prometheus.MustRegister(objectAgeSeconds, objectSizeBytes)
+}
+
+// ResetObjectMetricsForTarget clears every previously observed object
+// age/size histogram series for s3Target. A live ListObjectsV2 walk
+// re-observes every object in scope on each scrape, so without a reset the
+// cumulative histograms would grow by the full object set every cycle -
+// making rate(..._count) report phantom churn and ..._sum meaningless for
+// what's meant to be a snapshot distribution, not an ever-accumulating one.
+// Call it once per scrape cycle before any observeObjectMetrics calls for
+// that target.
+func ResetObjectMetricsForTarget(s3Target string) {
+	histogramsMutex.RLock()
+	defer histogramsMutex.RUnlock()
+
+	objectAgeSeconds.DeletePartialMatch(prometheus.Labels{"s3Target": s3Target})
+	objectSizeBytes.DeletePartialMatch(prometheus.Labels{"s3Target": s3Target})
+}
+
+// observeObjectMetrics records one object's age and size against its
+// target, bucket and storage class.
+func observeObjectMetrics(s3Target, bucketName, storageClass string, size float64, lastModified *time.Time) {
+	histogramsMutex.RLock()
+	defer histogramsMutex.RUnlock()
+
+	objectSizeBytes.WithLabelValues(s3Target, bucketName, storageClass).Observe(size)
+	if lastModified != nil {
+		objectAgeSeconds.WithLabelValues(s3Target, bucketName, storageClass).Observe(time.Since(*lastModified).Seconds())
+	}
+}
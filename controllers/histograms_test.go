@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveObjectMetrics(t *testing.T) {
+	before := testutil.CollectAndCount(objectSizeBytes)
+
+	lastModified := time.Now().Add(-48 * time.Hour)
+	observeObjectMetrics("target-a", "test-bucket", "STANDARD", 2048, &lastModified)
+
+	assert.Greater(t, testutil.CollectAndCount(objectSizeBytes), before)
+	assert.Greater(t, testutil.CollectAndCount(objectAgeSeconds), 0)
+}
+
+func TestResetObjectMetricsForTarget(t *testing.T) {
+	lastModified := time.Now()
+	observeObjectMetrics("target-reset", "test-bucket", "STANDARD", 1024, &lastModified)
+	observeObjectMetrics("target-other", "test-bucket", "STANDARD", 1024, &lastModified)
+
+	before := testutil.CollectAndCount(objectSizeBytes)
+	ResetObjectMetricsForTarget("target-reset")
+	after := testutil.CollectAndCount(objectSizeBytes)
+
+	assert.Equal(t, before-1, after)
+
+	observeObjectMetrics("target-other", "test-bucket", "STANDARD", 1024, &lastModified)
+	assert.Equal(t, after, testutil.CollectAndCount(objectSizeBytes))
+}
+
+func TestSetHistogramBuckets(t *testing.T) {
+	SetHistogramBuckets([]float64{1, 2, 3}, []float64{10, 20, 30})
+	defer SetHistogramBuckets(DefaultObjectAgeBuckets, DefaultObjectSizeBuckets)
+
+	lastModified := time.Now()
+	observeObjectMetrics("target-a", "test-bucket", "STANDARD", 15, &lastModified)
+
+	assert.Greater(t, testutil.CollectAndCount(objectSizeBytes), 0)
+}
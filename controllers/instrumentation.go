@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	apiRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3_api_requests_total",
+		Help: "Total number of S3 API calls by operation, bucket and result",
+	}, []string{"operation", "bucket", "result", "s3Target"})
+
+	apiErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3_api_errors_total",
+		Help: "Total number of failed S3 API calls by operation, bucket and AWS error code",
+	}, []string{"operation", "bucket", "code", "s3Target"})
+
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "s3_api_request_duration_seconds",
+		Help: "Duration of S3 API calls by operation and bucket",
+	}, []string{"operation", "bucket", "s3Target"})
+)
+
+func init() {
+	prometheus.MustRegister(apiRequestsTotal, apiErrorsTotal, apiRequestDuration)
+}
+
+// RegisterAPIMetrics registers the S3 API call counters/histogram against
+// reg in addition to the default registerer they're always registered
+// against in init. Multi-target mode serves each target from its own
+// private prometheus.Registry rather than the default one, so without this
+// these metrics would never appear in any target's /metrics response.
+func RegisterAPIMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(apiRequestsTotal, apiErrorsTotal, apiRequestDuration)
+}
+
+// instrumentedS3Client wraps an S3ClientInterface and records per-operation
+// request counts, error codes, and latency, so throttling and permission
+// errors that fetchBucketData otherwise swallows into a single errs slice
+// are visible in Prometheus. s3Target scopes those series to the target the
+// client belongs to, so two targets calling the same bucket name don't merge
+// each other's counts.
+type instrumentedS3Client struct {
+	inner    S3ClientInterface
+	s3Target string
+}
+
+// NewInstrumentedS3Client wraps client with request/error/latency metrics
+// scoped to s3Target (pass "" for the single-target, non --config.file,
+// setup).
+func NewInstrumentedS3Client(s3Target string, client S3ClientInterface) S3ClientInterface {
+	return &instrumentedS3Client{inner: client, s3Target: s3Target}
+}
+
+func (c *instrumentedS3Client) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+	start := time.Now()
+	out, err := c.inner.ListBuckets(ctx, params, optFns...)
+	observeAPICall("ListBuckets", "", c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	start := time.Now()
+	out, err := c.inner.ListObjectsV2(ctx, params, optFns...)
+	observeAPICall("ListObjectsV2", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	start := time.Now()
+	out, err := c.inner.GetObject(ctx, params, optFns...)
+	observeAPICall("GetObject", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+	start := time.Now()
+	out, err := c.inner.GetBucketLocation(ctx, params, optFns...)
+	observeAPICall("GetBucketLocation", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	start := time.Now()
+	out, err := c.inner.GetBucketTagging(ctx, params, optFns...)
+	observeAPICall("GetBucketTagging", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) GetBucketVersioning(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+	start := time.Now()
+	out, err := c.inner.GetBucketVersioning(ctx, params, optFns...)
+	observeAPICall("GetBucketVersioning", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+	start := time.Now()
+	out, err := c.inner.GetBucketEncryption(ctx, params, optFns...)
+	observeAPICall("GetBucketEncryption", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) GetPublicAccessBlock(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error) {
+	start := time.Now()
+	out, err := c.inner.GetPublicAccessBlock(ctx, params, optFns...)
+	observeAPICall("GetPublicAccessBlock", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) GetBucketReplication(ctx context.Context, params *s3.GetBucketReplicationInput, optFns ...func(*s3.Options)) (*s3.GetBucketReplicationOutput, error) {
+	start := time.Now()
+	out, err := c.inner.GetBucketReplication(ctx, params, optFns...)
+	observeAPICall("GetBucketReplication", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) GetBucketLifecycleConfiguration(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+	start := time.Now()
+	out, err := c.inner.GetBucketLifecycleConfiguration(ctx, params, optFns...)
+	observeAPICall("GetBucketLifecycleConfiguration", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) GetObjectLockConfiguration(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+	start := time.Now()
+	out, err := c.inner.GetObjectLockConfiguration(ctx, params, optFns...)
+	observeAPICall("GetObjectLockConfiguration", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+func (c *instrumentedS3Client) ListBucketIntelligentTieringConfigurations(ctx context.Context, params *s3.ListBucketIntelligentTieringConfigurationsInput, optFns ...func(*s3.Options)) (*s3.ListBucketIntelligentTieringConfigurationsOutput, error) {
+	start := time.Now()
+	out, err := c.inner.ListBucketIntelligentTieringConfigurations(ctx, params, optFns...)
+	observeAPICall("ListBucketIntelligentTieringConfigurations", aws.ToString(params.Bucket), c.s3Target, start, err)
+	return out, err
+}
+
+// observeAPICall records the outcome of a single S3 API call.
+func observeAPICall(operation, bucket, s3Target string, start time.Time, err error) {
+	apiRequestDuration.WithLabelValues(operation, bucket, s3Target).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		apiRequestsTotal.WithLabelValues(operation, bucket, "error", s3Target).Inc()
+		apiErrorsTotal.WithLabelValues(operation, bucket, awsErrorCode(err), s3Target).Inc()
+		return
+	}
+
+	apiRequestsTotal.WithLabelValues(operation, bucket, "success", s3Target).Inc()
+}
+
+// awsErrorCode unwraps err looking for a smithy.APIError to extract the AWS
+// error code (e.g. NoSuchBucket, AccessDenied, SlowDown), falling back to
+// "unknown" when the error doesn't carry one.
+func awsErrorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return "unknown"
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAwsErrorCode(t *testing.T) {
+	assert.Equal(t, "unknown", awsErrorCode(plainError("boom")))
+	assert.Equal(t, "NoSuchBucket", awsErrorCode(&smithy.GenericAPIError{Code: "NoSuchBucket"}))
+}
+
+type plainError string
+
+func (e plainError) Error() string { return string(e) }
+
+func TestInstrumentedS3Client_RecordsSuccessAndErrors(t *testing.T) {
+	mockClient := new(MockS3Client)
+	client := NewInstrumentedS3Client("target1", mockClient)
+
+	mockClient.On("ListObjectsV2", mock.Anything, mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents:    []types.Object{{Size: aws.Int64(1)}},
+		IsTruncated: aws.Bool(false),
+	}, nil).Once()
+
+	_, err := client.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{Bucket: aws.String("bucket1")})
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(apiRequestsTotal.WithLabelValues("ListObjectsV2", "bucket1", "success", "target1")))
+
+	mockClient.On("ListObjectsV2", mock.Anything, mock.Anything, mock.Anything).Return(
+		(*s3.ListObjectsV2Output)(nil), &smithy.GenericAPIError{Code: "AccessDenied"},
+	).Once()
+
+	_, err = client.ListObjectsV2(context.Background(), &s3.ListObjectsV2Input{Bucket: aws.String("bucket1")})
+	require.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(apiRequestsTotal.WithLabelValues("ListObjectsV2", "bucket1", "error", "target1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(apiErrorsTotal.WithLabelValues("ListObjectsV2", "bucket1", "AccessDenied", "target1")))
+}
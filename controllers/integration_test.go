@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFaultyFakeS3Server wraps a gofakes3 server with a handler that returns
+// a 500 for any request path containing faultyBucket, so tests can exercise
+// the same 5xx handling a real, partially-unhealthy S3 endpoint would trigger.
+func newFaultyFakeS3Server(t *testing.T, faultyBucket string) *httptest.Server {
+	t.Helper()
+
+	backend := s3mem.New()
+	faker := gofakes3.New(backend)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, faultyBucket) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		faker.Server().ServeHTTP(w, r)
+	})
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestS3UsageInfo_FakeServer_WildcardBucketList(t *testing.T) {
+	server := newFakeS3Server(t)
+	client := newFakeS3Client(t, server)
+	SetS3Client(client)
+	defer ResetS3Client()
+
+	// gofakes3/s3mem doesn't persist PutObjectInput.StorageClass (it always
+	// reports objects back as STANDARD through ListObjectsV2), so both
+	// buckets are seeded as STANDARD and totals are summed across them
+	// instead of asserting cross-class differentiation.
+	client.seedBucket(t, "wildcard-bucket-a", "obj/", 2, 256, "STANDARD")
+	client.seedBucket(t, "wildcard-bucket-b", "obj/", 3, 128, "STANDARD")
+
+	s3Conn := S3Conn{Region: "us-east-1", Endpoint: server.URL, AWSConfig: &aws.Config{}}
+
+	summary, err := S3UsageInfo(s3Conn, "", "")
+
+	require.NoError(t, err)
+	assert.True(t, summary.EndpointStatus)
+	assert.Len(t, summary.S3Buckets, 2)
+	assert.Equal(t, float64(2*256+3*128), summary.StorageClasses["STANDARD"].Size)
+}
+
+func TestS3UsageInfo_FakeServer_PaginatesAcrossManyKeys(t *testing.T) {
+	server := newFakeS3Server(t)
+	client := newFakeS3Client(t, server)
+	SetS3Client(client)
+	defer ResetS3Client()
+
+	const objectCount = 1200
+	client.seedBucket(t, "paginated-bucket", "obj/", objectCount, 64, "STANDARD")
+
+	s3Conn := S3Conn{Region: "us-east-1", Endpoint: server.URL, AWSConfig: &aws.Config{}}
+
+	summary, err := S3UsageInfo(s3Conn, "paginated-bucket", "")
+
+	require.NoError(t, err)
+	assert.True(t, summary.EndpointStatus)
+	require.Len(t, summary.S3Buckets, 1)
+	assert.Equal(t, float64(objectCount), summary.StorageClasses["STANDARD"].ObjectNumber)
+	assert.Equal(t, float64(objectCount*64), summary.StorageClasses["STANDARD"].Size)
+}
+
+func TestS3UsageInfo_FakeServer_BucketErrorLeavesEndpointDown(t *testing.T) {
+	server := newFaultyFakeS3Server(t, "broken-bucket")
+	client := newFakeS3Client(t, server)
+	SetS3Client(client)
+	defer ResetS3Client()
+
+	s3Conn := S3Conn{Region: "us-east-1", Endpoint: server.URL, AWSConfig: &aws.Config{}}
+
+	summary, err := S3UsageInfo(s3Conn, "broken-bucket", "")
+
+	require.NoError(t, err)
+	assert.False(t, summary.EndpointStatus)
+	assert.Empty(t, summary.S3Buckets)
+}
+
+func TestS3UsageInfo_FakeServer_PartialBucketErrorStillReportsHealthy(t *testing.T) {
+	server := newFaultyFakeS3Server(t, "broken-bucket")
+	client := newFakeS3Client(t, server)
+	SetS3Client(client)
+	defer ResetS3Client()
+
+	client.seedBucket(t, "healthy-bucket", "obj/", 2, 512, "STANDARD")
+
+	s3Conn := S3Conn{Region: "us-east-1", Endpoint: server.URL, AWSConfig: &aws.Config{}}
+
+	summary, err := S3UsageInfo(s3Conn, "healthy-bucket,broken-bucket", "")
+
+	require.NoError(t, err)
+	assert.True(t, summary.EndpointStatus)
+	require.Len(t, summary.S3Buckets, 1)
+	assert.Equal(t, "healthy-bucket", summary.S3Buckets[0].BucketName)
+}
@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// InventoryConfig points at an S3 Inventory report used instead of walking a
+// bucket with ListObjectsV2. Manifests are expected at the standard
+// s3://<Bucket>/<Prefix>/<sourceBucket>/<config-id>/<timestamp>/manifest.json
+// layout that S3 Inventory writes.
+type InventoryConfig struct {
+	Bucket string
+	Prefix string
+	Format string // not consulted: the report's own manifest fileFormat decides, and only "CSV" is read; any other falls back to ListObjectsV2
+
+	// MaxAge bounds how stale a manifest may be before it is rejected in
+	// favor of the ListObjectsV2 fallback. Zero means no freshness check.
+	MaxAge time.Duration
+}
+
+// inventoryKey scopes a registered InventoryConfig to the target it was
+// configured on, so two targets that happen to scrape a same-named bucket
+// never clobber each other's inventory source.
+type inventoryKey struct {
+	target string
+	bucket string
+}
+
+var (
+	inventoryConfigsMutex sync.RWMutex
+	inventoryConfigs      = map[inventoryKey]InventoryConfig{}
+
+	inventoryReportAge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "s3_inventory_report_age_seconds",
+		Help: "Age of the most recently ingested S3 Inventory report for a bucket",
+	}, []string{"s3Target", "bucketName"})
+
+	inventoryReportTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "s3_inventory_report_timestamp_seconds",
+		Help: "Unix timestamp of the most recently ingested S3 Inventory report for a bucket",
+	}, []string{"s3Target", "bucketName"})
+)
+
+func init() {
+	prometheus.MustRegister(inventoryReportAge, inventoryReportTimestamp)
+}
+
+// RegisterInventoryMetrics registers the inventory report age/timestamp
+// gauges against reg in addition to the default registerer they're always
+// registered against in init. Multi-target mode serves each target from its
+// own private prometheus.Registry rather than the default one, so without
+// this these metrics would never appear in any target's /metrics response.
+func RegisterInventoryMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(inventoryReportAge, inventoryReportTimestamp)
+}
+
+// SetInventoryConfig registers bucketName on targetName to be sourced from
+// an S3 Inventory report rather than a live ListObjectsV2 walk.
+func SetInventoryConfig(targetName, bucketName string, cfg InventoryConfig) {
+	inventoryConfigsMutex.Lock()
+	defer inventoryConfigsMutex.Unlock()
+	inventoryConfigs[inventoryKey{targetName, bucketName}] = cfg
+}
+
+// ConfigureInventoryForBuckets registers cfg for every bucket name in
+// bucketNamesCSV, the same comma-separated format accepted by
+// S3_BUCKET_NAMES/bucket_names, scoped to targetName. Inventory mode has no
+// way to discover bucket names on its own, so it only applies to buckets
+// named explicitly.
+func ConfigureInventoryForBuckets(targetName, bucketNamesCSV string, cfg InventoryConfig) {
+	for _, name := range strings.Split(bucketNamesCSV, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		SetInventoryConfig(targetName, name, cfg)
+	}
+}
+
+// ResetInventoryConfigs clears all registered inventory configs (used by tests).
+func ResetInventoryConfigs() {
+	inventoryConfigsMutex.Lock()
+	defer inventoryConfigsMutex.Unlock()
+	inventoryConfigs = map[inventoryKey]InventoryConfig{}
+}
+
+// computeBucketMetrics picks between the event-driven counters, the S3
+// Inventory data source, and the live ListObjectsV2 walk, in that order of
+// preference, depending on what's configured for bucketName on targetName.
+// objectPrefix only applies to the ListObjectsV2 walk; inventory reports and
+// event counters both cover the whole bucket. listing controls the parallel
+// prefix-sharded strategy used for the live ListObjectsV2 walk when it falls
+// through to one.
+func computeBucketMetrics(targetName, bucketName string, s3Client S3ClientInterface, objectPrefix string, listing ListingConfig) (map[string]StorageClassMetrics, time.Duration, int, error) {
+	if classes, fresh := eventCountersIfFresh(targetName, bucketName); fresh {
+		return classes, 0, 0, nil
+	}
+
+	inventoryConfigsMutex.RLock()
+	cfg, ok := inventoryConfigs[inventoryKey{targetName, bucketName}]
+	inventoryConfigsMutex.RUnlock()
+
+	if !ok {
+		storageClasses, duration, apiCalls, err := listBucketObjects(targetName, bucketName, s3Client, objectPrefix, listing)
+		if err == nil {
+			reconcileIfEventsConfigured(targetName, bucketName, storageClasses)
+		}
+		return storageClasses, duration, apiCalls, err
+	}
+
+	storageClasses, duration, apiCalls, err := calculateBucketMetricsFromInventory(targetName, bucketName, cfg, s3Client)
+	if err != nil {
+		log.Errorf("Inventory read failed for bucket %s, falling back to ListObjectsV2: %v", bucketName, err)
+		storageClasses, duration, apiCalls, err = listBucketObjects(targetName, bucketName, s3Client, objectPrefix, listing)
+		if err == nil {
+			reconcileIfEventsConfigured(targetName, bucketName, storageClasses)
+		}
+		return storageClasses, duration, apiCalls, err
+	}
+
+	return storageClasses, duration, apiCalls, nil
+}
+
+// listBucketObjects picks between the parallel prefix-sharded listing and
+// the serial calculateBucketMetrics, depending on listing.Workers.
+func listBucketObjects(targetName, bucketName string, s3Client S3ClientInterface, objectPrefix string, listing ListingConfig) (map[string]StorageClassMetrics, time.Duration, int, error) {
+	if listing.Workers > 1 {
+		return calculateBucketMetricsSharded(targetName, bucketName, s3Client, objectPrefix, listing)
+	}
+	return calculateBucketMetrics(targetName, bucketName, s3Client, objectPrefix)
+}
+
+// inventoryManifest is the subset of the S3 Inventory manifest.json schema
+// needed to locate and parse its data files.
+type inventoryManifest struct {
+	FileFormat string `json:"fileFormat"`
+	FileSchema string `json:"fileSchema"`
+	Files      []struct {
+		Key string `json:"key"`
+	} `json:"files"`
+}
+
+// calculateBucketMetricsFromInventory aggregates Size/StorageClass from the
+// most recent S3 Inventory report for sourceBucket instead of paginating
+// ListObjectsV2, which is the only viable option once a bucket holds
+// hundreds of millions of objects. apiCalls counts the list/get calls made
+// to locate and read the report.
+func calculateBucketMetricsFromInventory(targetName, sourceBucket string, cfg InventoryConfig, s3Client S3ClientInterface) (map[string]StorageClassMetrics, time.Duration, int, error) {
+	start := time.Now()
+	ctx := context.Background()
+	apiCalls := 0
+
+	manifestKey, manifestTimestamp, manifestListCalls, err := latestInventoryManifest(ctx, s3Client, cfg.Bucket, cfg.Prefix, sourceBucket)
+	apiCalls += manifestListCalls
+	if err != nil {
+		return nil, 0, apiCalls, err
+	}
+
+	inventoryReportAge.WithLabelValues(targetName, sourceBucket).Set(time.Since(manifestTimestamp).Seconds())
+	inventoryReportTimestamp.WithLabelValues(targetName, sourceBucket).Set(float64(manifestTimestamp.Unix()))
+
+	if cfg.MaxAge > 0 {
+		if age := time.Since(manifestTimestamp); age > cfg.MaxAge {
+			return nil, 0, apiCalls, fmt.Errorf("inventory report for bucket %s is %s old, exceeding max age %s", sourceBucket, age.Round(time.Second), cfg.MaxAge)
+		}
+	}
+
+	manifest, err := getInventoryManifest(ctx, s3Client, cfg.Bucket, manifestKey)
+	apiCalls++
+	if err != nil {
+		return nil, 0, apiCalls, err
+	}
+
+	if !strings.EqualFold(manifest.FileFormat, "CSV") {
+		return nil, 0, apiCalls, fmt.Errorf("unsupported inventory file format %q for bucket %s", manifest.FileFormat, sourceBucket)
+	}
+
+	sizeIdx, classIdx, err := inventorySchemaIndexes(manifest.FileSchema)
+	if err != nil {
+		return nil, 0, apiCalls, err
+	}
+
+	storageClasses := make(map[string]StorageClassMetrics)
+	for _, f := range manifest.Files {
+		if err := aggregateInventoryDataFile(ctx, s3Client, cfg.Bucket, f.Key, sizeIdx, classIdx, storageClasses); err != nil {
+			return nil, 0, apiCalls, err
+		}
+		apiCalls++
+	}
+
+	return storageClasses, time.Since(start), apiCalls, nil
+}
+
+// latestInventoryManifest lists manifest.json objects under
+// <prefix>/<sourceBucket>/ and returns the most recent one, since S3
+// Inventory timestamp folders (YYYY-MM-DDTHH-MMZ) sort lexically by time.
+// apiCalls counts the ListObjectsV2 pages fetched along the way.
+func latestInventoryManifest(ctx context.Context, s3Client S3ClientInterface, invBucket, prefix, sourceBucket string) (key string, timestamp time.Time, apiCalls int, err error) {
+	listPrefix := strings.TrimSuffix(prefix, "/") + "/" + sourceBucket + "/"
+
+	var manifestKeys []string
+	var continuationToken *string
+	for {
+		page, err := s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(invBucket),
+			Prefix:            aws.String(listPrefix),
+			ContinuationToken: continuationToken,
+		})
+		apiCalls++
+		if err != nil {
+			return "", time.Time{}, apiCalls, fmt.Errorf("listing inventory manifests for %s: %w", sourceBucket, err)
+		}
+
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if strings.HasSuffix(key, "/manifest.json") {
+				manifestKeys = append(manifestKeys, key)
+			}
+		}
+
+		if page.IsTruncated != nil && !*page.IsTruncated {
+			break
+		}
+		continuationToken = page.NextContinuationToken
+	}
+
+	if len(manifestKeys) == 0 {
+		return "", time.Time{}, apiCalls, fmt.Errorf("no inventory manifest found under s3://%s/%s", invBucket, listPrefix)
+	}
+
+	sort.Strings(manifestKeys)
+	latest := manifestKeys[len(manifestKeys)-1]
+
+	timestamp = inventoryTimestampFromKey(latest)
+	return latest, timestamp, apiCalls, nil
+}
+
+// inventoryTimestampFromKey extracts the YYYY-MM-DDTHH-MMZ timestamp folder
+// from a manifest key, defaulting to now if the layout doesn't match so a
+// malformed key degrades to "report age unknown" rather than failing.
+func inventoryTimestampFromKey(key string) time.Time {
+	parts := strings.Split(strings.TrimSuffix(key, "/manifest.json"), "/")
+	if len(parts) == 0 {
+		return time.Now()
+	}
+
+	if ts, err := time.Parse("2006-01-02T15-04Z", parts[len(parts)-1]); err == nil {
+		return ts
+	}
+	return time.Now()
+}
+
+func getInventoryManifest(ctx context.Context, s3Client S3ClientInterface, bucket, key string) (*inventoryManifest, error) {
+	out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, fmt.Errorf("fetching inventory manifest %s: %w", key, err)
+	}
+	defer out.Body.Close()
+
+	var manifest inventoryManifest
+	if err := json.NewDecoder(out.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("parsing inventory manifest %s: %w", key, err)
+	}
+
+	return &manifest, nil
+}
+
+// inventorySchemaIndexes maps the comma-separated fileSchema (e.g. "Bucket,
+// Key, Size, LastModifiedDate, StorageClass") to the column indexes needed
+// to aggregate size and storage class.
+func inventorySchemaIndexes(fileSchema string) (sizeIdx, classIdx int, err error) {
+	sizeIdx, classIdx = -1, -1
+	for i, col := range strings.Split(fileSchema, ",") {
+		switch strings.ToLower(strings.TrimSpace(col)) {
+		case "size":
+			sizeIdx = i
+		case "storageclass":
+			classIdx = i
+		}
+	}
+
+	if sizeIdx == -1 || classIdx == -1 {
+		return 0, 0, fmt.Errorf("inventory fileSchema %q is missing Size or StorageClass", fileSchema)
+	}
+
+	return sizeIdx, classIdx, nil
+}
+
+// aggregateInventoryDataFile streams one gzip-compressed CSV inventory data
+// file and adds its rows into storageClasses.
+func aggregateInventoryDataFile(ctx context.Context, s3Client S3ClientInterface, bucket, key string, sizeIdx, classIdx int, storageClasses map[string]StorageClassMetrics) error {
+	out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return fmt.Errorf("fetching inventory data file %s: %w", key, err)
+	}
+	defer out.Body.Close()
+
+	reader, err := gzip.NewReader(out.Body)
+	if err != nil {
+		return fmt.Errorf("decompressing inventory data file %s: %w", key, err)
+	}
+	defer reader.Close()
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading inventory data file %s: %w", key, err)
+		}
+
+		if len(record) <= sizeIdx || len(record) <= classIdx {
+			continue
+		}
+
+		size, err := strconv.ParseFloat(strings.TrimSpace(record[sizeIdx]), 64)
+		if err != nil {
+			continue
+		}
+
+		storageClass := strings.TrimSpace(record[classIdx])
+		if storageClass == "" {
+			storageClass = "STANDARD"
+		}
+
+		metrics := storageClasses[storageClass]
+		metrics.Size += size
+		metrics.ObjectNumber++
+		storageClasses[storageClass] = metrics
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateBucketMetricsFromInventory(t *testing.T) {
+	server := newFakeS3Server(t)
+	client := newFakeS3Client(t, server)
+
+	invBucket := "inventory-reports"
+	sourceBucket := "prod-data"
+	manifestPrefix := "reports/" + sourceBucket + "/config-1/2024-01-02T00-00Z"
+
+	var gz bytes.Buffer
+	gzw := gzip.NewWriter(&gz)
+	_, err := gzw.Write([]byte(
+		"prod-data,key1,1024,2024-01-01T00:00:00.000Z,STANDARD\n" +
+			"prod-data,key2,2048,2024-01-01T00:00:00.000Z,STANDARD\n" +
+			"prod-data,key3,4096,2024-01-01T00:00:00.000Z,GLACIER\n",
+	))
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+
+	putRawObject(t, client, invBucket, manifestPrefix+"/data-1.csv.gz", gz.Bytes())
+	putRawObject(t, client, invBucket, manifestPrefix+"/manifest.json", []byte(`{
+		"sourceBucket": "prod-data",
+		"fileFormat": "CSV",
+		"fileSchema": "Bucket, Key, Size, LastModifiedDate, StorageClass",
+		"files": [{"key": "`+manifestPrefix+`/data-1.csv.gz"}]
+	}`))
+
+	ResetInventoryConfigs()
+	defer ResetInventoryConfigs()
+
+	cfg := InventoryConfig{Bucket: invBucket, Prefix: "reports", Format: "CSV"}
+	storageClasses, duration, _, err := calculateBucketMetricsFromInventory("target-a", sourceBucket, cfg, client)
+
+	require.NoError(t, err)
+	require.Greater(t, duration.Nanoseconds(), int64(0))
+	require.Equal(t, float64(3072), storageClasses["STANDARD"].Size)
+	require.Equal(t, float64(2), storageClasses["STANDARD"].ObjectNumber)
+	require.Equal(t, float64(4096), storageClasses["GLACIER"].Size)
+	require.Equal(t, float64(1), storageClasses["GLACIER"].ObjectNumber)
+}
+
+func TestCalculateBucketMetricsFromInventory_StaleReportIsRejected(t *testing.T) {
+	server := newFakeS3Server(t)
+	client := newFakeS3Client(t, server)
+
+	invBucket := "inventory-reports"
+	sourceBucket := "prod-data"
+	manifestPrefix := "reports/" + sourceBucket + "/config-1/2024-01-02T00-00Z"
+
+	putRawObject(t, client, invBucket, manifestPrefix+"/manifest.json", []byte(`{
+		"sourceBucket": "prod-data",
+		"fileFormat": "CSV",
+		"fileSchema": "Bucket, Key, Size, LastModifiedDate, StorageClass",
+		"files": []
+	}`))
+
+	ResetInventoryConfigs()
+	defer ResetInventoryConfigs()
+
+	cfg := InventoryConfig{Bucket: invBucket, Prefix: "reports", Format: "CSV", MaxAge: time.Hour}
+	_, _, _, err := calculateBucketMetricsFromInventory("target-a", sourceBucket, cfg, client)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeding max age")
+}
+
+func TestConfigureInventoryForBuckets(t *testing.T) {
+	ResetInventoryConfigs()
+	defer ResetInventoryConfigs()
+
+	cfg := InventoryConfig{Bucket: "inv-bucket", Prefix: "reports"}
+	ConfigureInventoryForBuckets("target-a", " bucket1 , bucket2,", cfg)
+
+	inventoryConfigsMutex.RLock()
+	defer inventoryConfigsMutex.RUnlock()
+	require.Equal(t, cfg, inventoryConfigs[inventoryKey{"target-a", "bucket1"}])
+	require.Equal(t, cfg, inventoryConfigs[inventoryKey{"target-a", "bucket2"}])
+	require.NotContains(t, inventoryConfigs, inventoryKey{"target-a", ""})
+}
+
+func TestComputeBucketMetrics_FallsBackWithoutInventoryConfig(t *testing.T) {
+	server := newFakeS3Server(t)
+	client := newFakeS3Client(t, server)
+	client.seedBucket(t, "plain-bucket", "obj/", 2, 100, "STANDARD")
+
+	ResetInventoryConfigs()
+	defer ResetInventoryConfigs()
+
+	storageClasses, _, _, err := computeBucketMetrics("target-a", "plain-bucket", client, "", ListingConfig{})
+
+	require.NoError(t, err)
+	require.Equal(t, float64(200), storageClasses["STANDARD"].Size)
+}
+
+// putRawObject uploads raw bytes to bucket/key, creating the bucket first.
+func putRawObject(t *testing.T, client *fakeS3Client, bucket, key string, body []byte) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, _ = client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)})
+
+	_, err := client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(body),
+	})
+	require.NoError(t, err)
+}
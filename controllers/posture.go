@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// PostureConfig gates the extra per-bucket API calls needed for security and
+// compliance posture metrics. Each one is a separate S3 API call beyond the
+// existing ListObjectsV2/GetBucketTagging calls, so operators opt in per
+// signal instead of always paying for all of them.
+type PostureConfig struct {
+	CollectVersioning         bool
+	CollectEncryption         bool
+	CollectPublicAccessBlock  bool
+	CollectReplication        bool
+	CollectLifecycleRules     bool
+	CollectObjectLock         bool
+	CollectIntelligentTiering bool
+}
+
+// BucketPosture holds the security/compliance signals fetchBucketPosture
+// collected for one bucket. Fields default to their zero value (disabled/not
+// configured) when the corresponding PostureConfig toggle is off or the API
+// call fails, the same "missing means false" convention tags.go uses for
+// untagged buckets.
+type BucketPosture struct {
+	VersioningEnabled             bool
+	EncryptionEnabled             bool
+	EncryptionAlgorithm           string
+	PublicAccessBlocked           bool
+	ReplicationConfigured         bool
+	ReplicationDestRegion         string
+	LifecycleRuleCount            int
+	ObjectLockEnabled             bool
+	IntelligentTieringConfigCount int
+}
+
+// fetchBucketPosture runs the API calls enabled in cfg for bucketName and
+// returns whatever it could determine, logging (not failing) on errors since
+// a bucket this exporter can list may still not grant access to these
+// config-reading calls.
+func fetchBucketPosture(ctx context.Context, s3Client S3ClientInterface, bucketName string, cfg PostureConfig) BucketPosture {
+	var posture BucketPosture
+
+	if cfg.CollectVersioning {
+		posture.VersioningEnabled = fetchVersioningEnabled(ctx, s3Client, bucketName)
+	}
+	if cfg.CollectEncryption {
+		posture.EncryptionEnabled, posture.EncryptionAlgorithm = fetchEncryptionStatus(ctx, s3Client, bucketName)
+	}
+	if cfg.CollectPublicAccessBlock {
+		posture.PublicAccessBlocked = fetchPublicAccessBlocked(ctx, s3Client, bucketName)
+	}
+	if cfg.CollectReplication {
+		posture.ReplicationConfigured, posture.ReplicationDestRegion = fetchReplicationStatus(ctx, s3Client, bucketName)
+	}
+	if cfg.CollectLifecycleRules {
+		posture.LifecycleRuleCount = fetchLifecycleRuleCount(ctx, s3Client, bucketName)
+	}
+	if cfg.CollectObjectLock {
+		posture.ObjectLockEnabled = fetchObjectLockEnabled(ctx, s3Client, bucketName)
+	}
+	if cfg.CollectIntelligentTiering {
+		posture.IntelligentTieringConfigCount = fetchIntelligentTieringConfigCount(ctx, s3Client, bucketName)
+	}
+
+	return posture
+}
+
+func fetchVersioningEnabled(ctx context.Context, s3Client S3ClientInterface, bucketName string) bool {
+	out, err := s3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: aws.String(bucketName)})
+	if err != nil {
+		log.Debugf("Could not fetch versioning status for bucket %s: %v", bucketName, err)
+		return false
+	}
+	return out.Status == types.BucketVersioningStatusEnabled
+}
+
+func fetchEncryptionStatus(ctx context.Context, s3Client S3ClientInterface, bucketName string) (bool, string) {
+	out, err := s3Client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{Bucket: aws.String(bucketName)})
+	if err != nil {
+		log.Debugf("Could not fetch encryption status for bucket %s: %v", bucketName, err)
+		return false, ""
+	}
+
+	if out.ServerSideEncryptionConfiguration == nil || len(out.ServerSideEncryptionConfiguration.Rules) == 0 {
+		return false, ""
+	}
+
+	rule := out.ServerSideEncryptionConfiguration.Rules[0]
+	if rule.ApplyServerSideEncryptionByDefault == nil {
+		return false, ""
+	}
+
+	return true, string(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm)
+}
+
+func fetchPublicAccessBlocked(ctx context.Context, s3Client S3ClientInterface, bucketName string) bool {
+	out, err := s3Client.GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{Bucket: aws.String(bucketName)})
+	if err != nil {
+		log.Debugf("Could not fetch public access block status for bucket %s: %v", bucketName, err)
+		return false
+	}
+
+	cfg := out.PublicAccessBlockConfiguration
+	if cfg == nil {
+		return false
+	}
+
+	return aws.ToBool(cfg.BlockPublicAcls) && aws.ToBool(cfg.BlockPublicPolicy) &&
+		aws.ToBool(cfg.IgnorePublicAcls) && aws.ToBool(cfg.RestrictPublicBuckets)
+}
+
+func fetchReplicationStatus(ctx context.Context, s3Client S3ClientInterface, bucketName string) (bool, string) {
+	out, err := s3Client.GetBucketReplication(ctx, &s3.GetBucketReplicationInput{Bucket: aws.String(bucketName)})
+	if err != nil {
+		log.Debugf("Could not fetch replication status for bucket %s: %v", bucketName, err)
+		return false, ""
+	}
+
+	if out.ReplicationConfiguration == nil || len(out.ReplicationConfiguration.Rules) == 0 {
+		return false, ""
+	}
+
+	rule := out.ReplicationConfiguration.Rules[0]
+	if rule.Destination == nil || rule.Destination.Bucket == nil {
+		return true, ""
+	}
+
+	// S3 bucket ARNs ("arn:aws:s3:::destination-bucket") carry no region
+	// segment, so the destination's region has to be looked up the same way
+	// discoverBucketRegion resolves any other bucket's region.
+	destBucket := strings.TrimPrefix(aws.ToString(rule.Destination.Bucket), "arn:aws:s3:::")
+	destRegion, err := discoverBucketRegion(ctx, s3Client, destBucket)
+	if err != nil {
+		log.Debugf("Could not discover region for replication destination %s: %v", destBucket, err)
+		return true, ""
+	}
+
+	return true, destRegion
+}
+
+func fetchLifecycleRuleCount(ctx context.Context, s3Client S3ClientInterface, bucketName string) int {
+	out, err := s3Client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: aws.String(bucketName)})
+	if err != nil {
+		log.Debugf("Could not fetch lifecycle configuration for bucket %s: %v", bucketName, err)
+		return 0
+	}
+	return len(out.Rules)
+}
+
+func fetchObjectLockEnabled(ctx context.Context, s3Client S3ClientInterface, bucketName string) bool {
+	out, err := s3Client.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{Bucket: aws.String(bucketName)})
+	if err != nil {
+		log.Debugf("Could not fetch object lock configuration for bucket %s: %v", bucketName, err)
+		return false
+	}
+
+	if out.ObjectLockConfiguration == nil {
+		return false
+	}
+
+	return out.ObjectLockConfiguration.ObjectLockEnabled == types.ObjectLockEnabledEnabled
+}
+
+// fetchIntelligentTieringConfigCount counts the S3 Intelligent-Tiering
+// configurations on bucketName, paginating through all of them since
+// ListBucketIntelligentTieringConfigurations caps each page at 100 entries.
+func fetchIntelligentTieringConfigCount(ctx context.Context, s3Client S3ClientInterface, bucketName string) int {
+	count := 0
+	var continuationToken *string
+
+	for {
+		out, err := s3Client.ListBucketIntelligentTieringConfigurations(ctx, &s3.ListBucketIntelligentTieringConfigurationsInput{
+			Bucket:            aws.String(bucketName),
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			log.Debugf("Could not fetch intelligent tiering configurations for bucket %s: %v", bucketName, err)
+			return count
+		}
+
+		count += len(out.IntelligentTieringConfigurationList)
+
+		if out.IsTruncated == nil || !*out.IsTruncated {
+			break
+		}
+		continuationToken = out.NextContinuationToken
+	}
+
+	return count
+}
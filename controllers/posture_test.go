@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchBucketPosture_AllDisabledSkipsEveryCall(t *testing.T) {
+	mockClient := new(MockS3Client)
+
+	posture := fetchBucketPosture(context.Background(), mockClient, "bucket1", PostureConfig{})
+
+	assert.Equal(t, BucketPosture{}, posture)
+	mockClient.AssertNotCalled(t, "GetBucketVersioning", mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "GetBucketEncryption", mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "GetPublicAccessBlock", mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "GetBucketReplication", mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "GetBucketLifecycleConfiguration", mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "GetObjectLockConfiguration", mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "ListBucketIntelligentTieringConfigurations", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFetchVersioningEnabled(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketVersioning", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketVersioningOutput{
+		Status: types.BucketVersioningStatusEnabled,
+	}, nil)
+
+	assert.True(t, fetchVersioningEnabled(context.Background(), mockClient, "bucket1"))
+}
+
+func TestFetchVersioningEnabled_NotConfigured(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketVersioning", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketVersioningOutput{}, nil)
+
+	assert.False(t, fetchVersioningEnabled(context.Background(), mockClient, "bucket1"))
+}
+
+func TestFetchEncryptionStatus(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketEncryption", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketEncryptionOutput{
+		ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
+			Rules: []types.ServerSideEncryptionRule{
+				{ApplyServerSideEncryptionByDefault: &types.ServerSideEncryptionByDefault{SSEAlgorithm: types.ServerSideEncryptionAwsKms}},
+			},
+		},
+	}, nil)
+
+	enabled, algorithm := fetchEncryptionStatus(context.Background(), mockClient, "bucket1")
+
+	assert.True(t, enabled)
+	assert.Equal(t, "aws:kms", algorithm)
+}
+
+func TestFetchEncryptionStatus_Error(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketEncryption", mock.Anything, mock.Anything, mock.Anything).Return((*s3.GetBucketEncryptionOutput)(nil), assert.AnError)
+
+	enabled, algorithm := fetchEncryptionStatus(context.Background(), mockClient, "bucket1")
+
+	assert.False(t, enabled)
+	assert.Empty(t, algorithm)
+}
+
+func TestFetchPublicAccessBlocked_AllFourSettings(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetPublicAccessBlock", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetPublicAccessBlockOutput{
+		PublicAccessBlockConfiguration: &types.PublicAccessBlockConfiguration{
+			BlockPublicAcls:       aws.Bool(true),
+			BlockPublicPolicy:     aws.Bool(true),
+			IgnorePublicAcls:      aws.Bool(true),
+			RestrictPublicBuckets: aws.Bool(true),
+		},
+	}, nil)
+
+	assert.True(t, fetchPublicAccessBlocked(context.Background(), mockClient, "bucket1"))
+}
+
+func TestFetchPublicAccessBlocked_PartialSettings(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetPublicAccessBlock", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetPublicAccessBlockOutput{
+		PublicAccessBlockConfiguration: &types.PublicAccessBlockConfiguration{
+			BlockPublicAcls:       aws.Bool(true),
+			BlockPublicPolicy:     aws.Bool(false),
+			IgnorePublicAcls:      aws.Bool(true),
+			RestrictPublicBuckets: aws.Bool(true),
+		},
+	}, nil)
+
+	assert.False(t, fetchPublicAccessBlocked(context.Background(), mockClient, "bucket1"))
+}
+
+func TestFetchReplicationStatus_ResolvesDestinationRegion(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketReplication", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketReplicationOutput{
+		ReplicationConfiguration: &types.ReplicationConfiguration{
+			Rules: []types.ReplicationRule{
+				{Destination: &types.Destination{Bucket: aws.String("arn:aws:s3:::dest-bucket")}},
+			},
+		},
+	}, nil)
+	mockClient.On("GetBucketLocation", mock.Anything, mock.MatchedBy(func(in *s3.GetBucketLocationInput) bool {
+		return aws.ToString(in.Bucket) == "dest-bucket"
+	}), mock.Anything).Return(&s3.GetBucketLocationOutput{LocationConstraint: types.BucketLocationConstraintEuWest1}, nil)
+
+	configured, region := fetchReplicationStatus(context.Background(), mockClient, "bucket1")
+
+	assert.True(t, configured)
+	assert.Equal(t, "eu-west-1", region)
+}
+
+func TestFetchReplicationStatus_NotConfigured(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketReplication", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketReplicationOutput{}, nil)
+
+	configured, region := fetchReplicationStatus(context.Background(), mockClient, "bucket1")
+
+	assert.False(t, configured)
+	assert.Empty(t, region)
+}
+
+func TestFetchLifecycleRuleCount(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketLifecycleConfiguration", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketLifecycleConfigurationOutput{
+		Rules: []types.LifecycleRule{{}, {}, {}},
+	}, nil)
+
+	assert.Equal(t, 3, fetchLifecycleRuleCount(context.Background(), mockClient, "bucket1"))
+}
+
+func TestFetchObjectLockEnabled(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetObjectLockConfiguration", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetObjectLockConfigurationOutput{
+		ObjectLockConfiguration: &types.ObjectLockConfiguration{ObjectLockEnabled: types.ObjectLockEnabledEnabled},
+	}, nil)
+
+	assert.True(t, fetchObjectLockEnabled(context.Background(), mockClient, "bucket1"))
+}
+
+func TestFetchIntelligentTieringConfigCount_SinglePage(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("ListBucketIntelligentTieringConfigurations", mock.Anything, mock.Anything, mock.Anything).Return(&s3.ListBucketIntelligentTieringConfigurationsOutput{
+		IntelligentTieringConfigurationList: []types.IntelligentTieringConfiguration{{}, {}},
+		IsTruncated:                         aws.Bool(false),
+	}, nil)
+
+	assert.Equal(t, 2, fetchIntelligentTieringConfigCount(context.Background(), mockClient, "bucket1"))
+}
+
+func TestFetchIntelligentTieringConfigCount_Paginates(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("ListBucketIntelligentTieringConfigurations", mock.Anything, mock.MatchedBy(func(in *s3.ListBucketIntelligentTieringConfigurationsInput) bool {
+		return in.ContinuationToken == nil
+	}), mock.Anything).Return(&s3.ListBucketIntelligentTieringConfigurationsOutput{
+		IntelligentTieringConfigurationList: []types.IntelligentTieringConfiguration{{}},
+		IsTruncated:                         aws.Bool(true),
+		NextContinuationToken:               aws.String("page2"),
+	}, nil).Once()
+	mockClient.On("ListBucketIntelligentTieringConfigurations", mock.Anything, mock.MatchedBy(func(in *s3.ListBucketIntelligentTieringConfigurationsInput) bool {
+		return aws.ToString(in.ContinuationToken) == "page2"
+	}), mock.Anything).Return(&s3.ListBucketIntelligentTieringConfigurationsOutput{
+		IntelligentTieringConfigurationList: []types.IntelligentTieringConfiguration{{}, {}},
+		IsTruncated:                         aws.Bool(false),
+	}, nil).Once()
+
+	assert.Equal(t, 3, fetchIntelligentTieringConfigCount(context.Background(), mockClient, "bucket1"))
+}
+
+func TestFetchIntelligentTieringConfigCount_Error(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("ListBucketIntelligentTieringConfigurations", mock.Anything, mock.Anything, mock.Anything).Return((*s3.ListBucketIntelligentTieringConfigurationsOutput)(nil), assert.AnError)
+
+	assert.Equal(t, 0, fetchIntelligentTieringConfigCount(context.Background(), mockClient, "bucket1"))
+}
+
+func TestFetchBucketPosture_OnlySelectedSignalsFetched(t *testing.T) {
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketVersioning", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketVersioningOutput{
+		Status: types.BucketVersioningStatusEnabled,
+	}, nil)
+
+	posture := fetchBucketPosture(context.Background(), mockClient, "bucket1", PostureConfig{CollectVersioning: true})
+
+	require.True(t, posture.VersioningEnabled)
+	mockClient.AssertNotCalled(t, "GetBucketEncryption", mock.Anything, mock.Anything, mock.Anything)
+}
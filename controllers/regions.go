@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// regionClientCache lazily builds and caches an S3ClientInterface per AWS
+// region, so a single scrape can talk to buckets that live outside its
+// configured s3Region (AWS otherwise answers cross-region redirects with
+// PermanentRedirect or AuthorizationHeaderMalformed) without reconstructing a
+// client for every bucket. Clients are keyed by (region, fallback) rather
+// than region alone, since fallback may be the target's own default client
+// or a per-bucket credential profile client, and a region override must be
+// built from whichever config produced fallback, not a single target-wide
+// one.
+type regionClientCache struct {
+	mu       sync.Mutex
+	clients  map[regionClientKey]S3ClientInterface
+	s3Target string
+}
+
+type regionClientKey struct {
+	region   string
+	fallback S3ClientInterface
+}
+
+// newRegionClientCache scopes any client it builds to s3Target (pass "" for
+// the single-target, non --config.file, setup).
+func newRegionClientCache(s3Target string) *regionClientCache {
+	return &regionClientCache{clients: make(map[regionClientKey]S3ClientInterface), s3Target: s3Target}
+}
+
+// clientFor returns a client scoped to region, constructing and caching one
+// on first use from awsConfig/forcePathStyle (the config that produced
+// fallback). When a client has been injected via SetS3Client (tests),
+// fallback is returned unconditionally so mocked expectations stay valid
+// regardless of the discovered region.
+func (c *regionClientCache) clientFor(region string, awsConfig *aws.Config, forcePathStyle bool, fallback S3ClientInterface) S3ClientInterface {
+	if s3ClientInstance != nil {
+		return fallback
+	}
+
+	key := regionClientKey{region: region, fallback: fallback}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[key]; ok {
+		return client
+	}
+
+	options := func(o *s3.Options) {
+		o.UsePathStyle = forcePathStyle
+		o.Region = region
+	}
+
+	client := NewInstrumentedS3Client(c.s3Target, s3.NewFromConfig(*awsConfig, options))
+	c.clients[key] = client
+	return client
+}
+
+// discoverBucketRegion looks up bucketName's region via GetBucketLocation,
+// mapping AWS's legacy empty LocationConstraint (returned for us-east-1) to
+// an explicit region name.
+func discoverBucketRegion(ctx context.Context, s3Client S3ClientInterface, bucketName string) (string, error) {
+	out, err := s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{Bucket: aws.String(bucketName)})
+	if err != nil {
+		return "", err
+	}
+
+	region := string(out.LocationConstraint)
+	if region == "" {
+		region = "us-east-1"
+	}
+	return region, nil
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverBucketRegion(t *testing.T) {
+	mockClient := new(MockS3Client)
+
+	mockClient.On("GetBucketLocation", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketLocationOutput{
+		LocationConstraint: types.BucketLocationConstraintEuWest1,
+	}, nil).Once()
+
+	region, err := discoverBucketRegion(context.Background(), mockClient, "bucket1")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", region)
+
+	mockClient.On("GetBucketLocation", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketLocationOutput{
+		LocationConstraint: "",
+	}, nil).Once()
+
+	region, err = discoverBucketRegion(context.Background(), mockClient, "bucket2")
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", region)
+}
+
+func TestRegionClientCache_ReturnsFallbackWhenClientInjected(t *testing.T) {
+	mockClient := new(MockS3Client)
+	SetS3Client(mockClient)
+	defer ResetS3Client()
+
+	cache := newRegionClientCache("")
+	assert.Same(t, S3ClientInterface(mockClient), cache.clientFor("eu-west-1", &aws.Config{}, false, mockClient))
+}
+
+func TestRegionClientCache_BuildsAndReusesClientPerRegion(t *testing.T) {
+	cache := newRegionClientCache("")
+	awsConfig := &aws.Config{Region: "us-east-1"}
+
+	first := cache.clientFor("eu-west-1", awsConfig, false, nil)
+	second := cache.clientFor("eu-west-1", awsConfig, false, nil)
+	third := cache.clientFor("us-west-2", awsConfig, false, nil)
+
+	assert.NotNil(t, first)
+	assert.Same(t, first, second)
+	assert.NotSame(t, first, third)
+}
+
+func TestRegionClientCache_ScopesRegionClientsPerFallbackIdentity(t *testing.T) {
+	cache := newRegionClientCache("")
+
+	defaultAWSConfig := &aws.Config{Region: "us-east-1"}
+	defaultFallback := new(MockS3Client)
+	profileAWSConfig := &aws.Config{Region: "us-east-1", BaseEndpoint: aws.String("https://tenant.example.com")}
+	profileFallback := new(MockS3Client)
+
+	defaultRegionClient := cache.clientFor("eu-west-1", defaultAWSConfig, false, defaultFallback)
+	profileRegionClient := cache.clientFor("eu-west-1", profileAWSConfig, false, profileFallback)
+
+	assert.NotSame(t, defaultRegionClient, profileRegionClient, "region clients built for different fallback clients must not share a cache entry")
+	assert.Same(t, defaultRegionClient, cache.clientFor("eu-west-1", defaultAWSConfig, false, defaultFallback))
+	assert.Same(t, profileRegionClient, cache.clientFor("eu-west-1", profileAWSConfig, false, profileFallback))
+}
@@ -22,8 +22,12 @@ type StorageClassMetrics struct {
 // Bucket - information per bucket
 type Bucket struct {
 	BucketName     string                         `json:"bucketName"`
+	BucketRegion   string                         `json:"bucketRegion"`
+	Tags           map[string]string              `json:"tags,omitempty"`
 	StorageClasses map[string]StorageClassMetrics `json:"storageClasses"`
 	ListDuration   time.Duration                  `json:"listDuration"`
+	ListAPICalls   int                            `json:"listApiCalls"`
+	Posture        BucketPosture                  `json:"posture"`
 }
 
 // Buckets - list of Bucket objects
@@ -43,6 +47,18 @@ type S3Conn struct {
 	Region         string      `json:"region"`
 	ForcePathStyle bool        `json:"force_path_style"`
 	AWSConfig      *aws.Config `json:"-"`
+
+	// Discovery filters the buckets found by ListBuckets when s3BucketNames
+	// is empty, and optionally scopes object listing to a sub-path.
+	Discovery BucketDiscovery `json:"discovery,omitempty"`
+
+	// Posture gates the extra per-bucket API calls behind the security and
+	// compliance posture metrics (versioning, encryption, ...).
+	Posture PostureConfig `json:"posture,omitempty"`
+
+	// Listing controls the parallel prefix-sharded listing strategy used for
+	// buckets too large to list serially within the scrape interval.
+	Listing ListingConfig `json:"listing,omitempty"`
 }
 
 // S3Collector struct
@@ -50,28 +66,76 @@ type S3Collector struct {
 	Metrics      S3Summary
 	metricsMutex sync.RWMutex
 	Err          error
+	s3Target     string
 	s3Endpoint   string
 	s3Region     string
+	posture      PostureConfig
 }
 
 type S3ClientInterface interface {
 	ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
 	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error)
+	GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error)
+	GetBucketVersioning(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error)
+	GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error)
+	GetPublicAccessBlock(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error)
+	GetBucketReplication(ctx context.Context, params *s3.GetBucketReplicationInput, optFns ...func(*s3.Options)) (*s3.GetBucketReplicationOutput, error)
+	GetBucketLifecycleConfiguration(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error)
+	GetObjectLockConfiguration(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error)
+	ListBucketIntelligentTieringConfigurations(ctx context.Context, params *s3.ListBucketIntelligentTieringConfigurationsInput, optFns ...func(*s3.Options)) (*s3.ListBucketIntelligentTieringConfigurationsOutput, error)
 }
 
 var (
 	s3ClientInstance S3ClientInterface
-	metricsDesc      = map[string]*prometheus.Desc{
-		"up":              prometheus.NewDesc("s3_endpoint_up", "Connection to S3 successful", []string{"s3Endpoint", "s3Region"}, nil),
-		"total_size":      prometheus.NewDesc("s3_total_size", "S3 Total Bucket Size", []string{"s3Endpoint", "s3Region", "storageClass"}, nil),
-		"total_objects":   prometheus.NewDesc("s3_total_object_number", "S3 Total Object Number", []string{"s3Endpoint", "s3Region", "storageClass"}, nil),
-		"total_duration":  prometheus.NewDesc("s3_list_total_duration_seconds", "Total time spent listing objects across all buckets", []string{"s3Endpoint", "s3Region"}, nil),
-		"bucket_size":     prometheus.NewDesc("s3_bucket_size", "S3 Bucket Size", []string{"s3Endpoint", "s3Region", "bucketName", "storageClass"}, nil),
-		"bucket_objects":  prometheus.NewDesc("s3_bucket_object_number", "S3 Bucket Object Number", []string{"s3Endpoint", "s3Region", "bucketName", "storageClass"}, nil),
-		"bucket_duration": prometheus.NewDesc("s3_list_duration_seconds", "Time spent listing objects in bucket", []string{"s3Endpoint", "s3Region", "bucketName"}, nil),
-	}
+	metricsDesc      = buildMetricsDesc()
 )
 
+// buildMetricsDesc constructs the package's prometheus.Desc set. Bucket-level
+// descriptors pick up the configured tag-label allowlist (see
+// SetBucketTagLabels) as extra variable labels, either just on s3_bucket_info
+// or, when attachTagLabelsToBucketMetrics is set, on the size/object/duration
+// metrics as well.
+func buildMetricsDesc() map[string]*prometheus.Desc {
+	bucketLabels := []string{"s3Target", "s3Endpoint", "s3Region", "bucketName", "bucketRegion"}
+	infoLabels := append(append([]string{}, bucketLabels...), bucketTagLabelKeys...)
+
+	if attachTagLabelsToBucketMetrics {
+		bucketLabels = append(append([]string{}, bucketLabels...), bucketTagLabelKeys...)
+	}
+	sizeLabels := append(append([]string{}, bucketLabels...), "storageClass")
+
+	replicationLabels := append(append([]string{}, bucketLabels...), "destinationRegion")
+	encryptionLabels := append(append([]string{}, bucketLabels...), "sseAlgorithm")
+
+	descs := map[string]*prometheus.Desc{
+		"up":                         prometheus.NewDesc("s3_endpoint_up", "Connection to S3 successful", []string{"s3Target", "s3Endpoint", "s3Region"}, nil),
+		"total_size":                 prometheus.NewDesc("s3_total_size", "S3 Total Bucket Size", []string{"s3Target", "s3Endpoint", "s3Region", "storageClass"}, nil),
+		"total_objects":              prometheus.NewDesc("s3_total_object_number", "S3 Total Object Number", []string{"s3Target", "s3Endpoint", "s3Region", "storageClass"}, nil),
+		"total_duration":             prometheus.NewDesc("s3_list_total_duration_seconds", "Total time spent listing objects across all buckets", []string{"s3Target", "s3Endpoint", "s3Region"}, nil),
+		"bucket_size":                prometheus.NewDesc("s3_bucket_size", "S3 Bucket Size", sizeLabels, nil),
+		"bucket_objects":             prometheus.NewDesc("s3_bucket_object_number", "S3 Bucket Object Number", sizeLabels, nil),
+		"bucket_duration":            prometheus.NewDesc("s3_list_duration_seconds", "Time spent listing objects in bucket", bucketLabels, nil),
+		"bucket_list_api_calls":      prometheus.NewDesc("s3_list_api_calls", "Number of S3 API calls (ListObjectsV2 pages, plus GetObject calls when read from an S3 Inventory report) made to compute the bucket's metrics on the last scrape", bucketLabels, nil),
+		"bucket_info":                prometheus.NewDesc("s3_bucket_info", "S3 bucket metadata as labels, value is always 1", infoLabels, nil),
+		"bucket_versioning":          prometheus.NewDesc("s3_bucket_versioning_enabled", "Whether bucket versioning is enabled (1) or not (0)", bucketLabels, nil),
+		"bucket_encryption":          prometheus.NewDesc("s3_bucket_encryption_enabled", "Whether default server-side encryption is enabled (1) or not (0)", encryptionLabels, nil),
+		"bucket_public_block":        prometheus.NewDesc("s3_bucket_public_access_block", "Whether all four S3 Block Public Access settings are enabled (1) or not (0)", bucketLabels, nil),
+		"bucket_replication":         prometheus.NewDesc("s3_bucket_replication_configured", "Whether bucket replication is configured (1) or not (0)", replicationLabels, nil),
+		"bucket_lifecycle_rules":     prometheus.NewDesc("s3_bucket_lifecycle_rules", "Number of lifecycle rules configured on the bucket", bucketLabels, nil),
+		"bucket_object_lock":         prometheus.NewDesc("s3_bucket_object_lock_enabled", "Whether S3 Object Lock is enabled (1) or not (0)", bucketLabels, nil),
+		"bucket_intelligent_tiering": prometheus.NewDesc("s3_bucket_intelligent_tiering_configuration_count", "Number of S3 Intelligent-Tiering configurations on the bucket", bucketLabels, nil),
+	}
+
+	if storageClassBreakdownEnabled() {
+		descs["bucket_size_by_class"] = prometheus.NewDesc("s3_bucket_size_bytes_by_class", "S3 Bucket Size broken down by storage class", sizeLabels, nil)
+		descs["bucket_objects_by_class"] = prometheus.NewDesc("s3_bucket_objects_by_class", "S3 Bucket Object Number broken down by storage class", sizeLabels, nil)
+	}
+
+	return descs
+}
+
 // SetS3Client sets the S3 client instance for testing
 func SetS3Client(client S3ClientInterface) {
 	s3ClientInstance = client
@@ -82,8 +146,11 @@ func ResetS3Client() {
 	s3ClientInstance = nil
 }
 
-// GetS3Client returns the S3 client instance or creates a new one
-func GetS3Client(s3Conn S3Conn) (S3ClientInterface, error) {
+// GetS3Client returns the S3 client instance or creates a new one scoped to
+// targetName (pass "" for the single-target, non --config.file, setup), so
+// the instrumented client's s3_api_requests_total/etc. series carry the
+// right s3Target label.
+func GetS3Client(s3Conn S3Conn, targetName string) (S3ClientInterface, error) {
 	if s3ClientInstance != nil {
 		return s3ClientInstance, nil
 	}
@@ -92,12 +159,20 @@ func GetS3Client(s3Conn S3Conn) (S3ClientInterface, error) {
 		o.UsePathStyle = s3Conn.ForcePathStyle
 	}
 
-	return s3.NewFromConfig(*s3Conn.AWSConfig, options), nil
+	return NewInstrumentedS3Client(targetName, s3.NewFromConfig(*s3Conn.AWSConfig, options)), nil
 }
 
-// NewS3Collector creates a new S3Collector
+// NewS3Collector creates a new S3Collector for the default (single-target) setup
 func NewS3Collector(s3Endpoint, s3Region string) *S3Collector {
+	return NewS3CollectorForTarget("", s3Endpoint, s3Region)
+}
+
+// NewS3CollectorForTarget creates a new S3Collector scoped to a named target,
+// so several collectors can be registered against separate (or shared)
+// prometheus registries without their metrics colliding.
+func NewS3CollectorForTarget(s3Target, s3Endpoint, s3Region string) *S3Collector {
 	return &S3Collector{
+		s3Target:   s3Target,
 		s3Endpoint: s3Endpoint,
 		s3Region:   s3Region,
 	}
@@ -115,6 +190,7 @@ func (c *S3Collector) Collect(ch chan<- prometheus.Metric) {
 	c.metricsMutex.RLock()
 	metrics := c.Metrics
 	err := c.Err
+	posture := c.posture
 	c.metricsMutex.RUnlock()
 
 	status := 0
@@ -123,37 +199,85 @@ func (c *S3Collector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	if err != nil {
-		ch <- prometheus.MustNewConstMetric(metricsDesc["up"], prometheus.GaugeValue, float64(status), c.s3Endpoint, c.s3Region)
+		ch <- prometheus.MustNewConstMetric(metricsDesc["up"], prometheus.GaugeValue, float64(status), c.s3Target, c.s3Endpoint, c.s3Region)
 		log.Errorf("Cached error: %v", err)
 		return
 	}
 
-	ch <- prometheus.MustNewConstMetric(metricsDesc["up"], prometheus.GaugeValue, float64(status), c.s3Endpoint, c.s3Region)
+	ch <- prometheus.MustNewConstMetric(metricsDesc["up"], prometheus.GaugeValue, float64(status), c.s3Target, c.s3Endpoint, c.s3Region)
 
 	// Global metrics
 	for class, s3Metrics := range metrics.StorageClasses {
-		ch <- prometheus.MustNewConstMetric(metricsDesc["total_size"], prometheus.GaugeValue, s3Metrics.Size, c.s3Endpoint, c.s3Region, class)
-		ch <- prometheus.MustNewConstMetric(metricsDesc["total_objects"], prometheus.GaugeValue, s3Metrics.ObjectNumber, c.s3Endpoint, c.s3Region, class)
+		ch <- prometheus.MustNewConstMetric(metricsDesc["total_size"], prometheus.GaugeValue, s3Metrics.Size, c.s3Target, c.s3Endpoint, c.s3Region, class)
+		ch <- prometheus.MustNewConstMetric(metricsDesc["total_objects"], prometheus.GaugeValue, s3Metrics.ObjectNumber, c.s3Target, c.s3Endpoint, c.s3Region, class)
 	}
-	ch <- prometheus.MustNewConstMetric(metricsDesc["total_duration"], prometheus.GaugeValue, float64(metrics.TotalListDuration.Seconds()), c.s3Endpoint, c.s3Region)
+	ch <- prometheus.MustNewConstMetric(metricsDesc["total_duration"], prometheus.GaugeValue, float64(metrics.TotalListDuration.Seconds()), c.s3Target, c.s3Endpoint, c.s3Region)
 
 	// Per-bucket metrics
 	for _, bucket := range metrics.S3Buckets {
+		bucketLabelValues := []string{c.s3Target, c.s3Endpoint, c.s3Region, bucket.BucketName, bucket.BucketRegion}
+		tagLabelValues := bucketTagLabelValues(bucket.Tags)
+		if attachTagLabelsToBucketMetrics {
+			bucketLabelValues = append(bucketLabelValues, tagLabelValues...)
+		}
+
 		for class, s3Metrics := range bucket.StorageClasses {
-			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_size"], prometheus.GaugeValue, s3Metrics.Size, c.s3Endpoint, c.s3Region, bucket.BucketName, class)
-			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_objects"], prometheus.GaugeValue, s3Metrics.ObjectNumber, c.s3Endpoint, c.s3Region, bucket.BucketName, class)
+			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_size"], prometheus.GaugeValue, s3Metrics.Size, append(append([]string{}, bucketLabelValues...), class)...)
+			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_objects"], prometheus.GaugeValue, s3Metrics.ObjectNumber, append(append([]string{}, bucketLabelValues...), class)...)
+			if storageClassBreakdownEnabled() {
+				ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_size_by_class"], prometheus.GaugeValue, s3Metrics.Size, append(append([]string{}, bucketLabelValues...), class)...)
+				ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_objects_by_class"], prometheus.GaugeValue, s3Metrics.ObjectNumber, append(append([]string{}, bucketLabelValues...), class)...)
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_duration"], prometheus.GaugeValue, float64(bucket.ListDuration.Seconds()), bucketLabelValues...)
+		ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_list_api_calls"], prometheus.GaugeValue, float64(bucket.ListAPICalls), bucketLabelValues...)
+		ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_info"], prometheus.GaugeValue, 1, append([]string{c.s3Target, c.s3Endpoint, c.s3Region, bucket.BucketName, bucket.BucketRegion}, tagLabelValues...)...)
+
+		// Each posture metric is only emitted when its collect flag is on,
+		// so "not collected" (no series) can't be mistaken for "disabled"
+		// (series present, value 0) by anyone scraping for a security or
+		// compliance signal.
+		if posture.CollectVersioning {
+			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_versioning"], prometheus.GaugeValue, boolToFloat(bucket.Posture.VersioningEnabled), bucketLabelValues...)
+		}
+		if posture.CollectEncryption {
+			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_encryption"], prometheus.GaugeValue, boolToFloat(bucket.Posture.EncryptionEnabled), append(append([]string{}, bucketLabelValues...), bucket.Posture.EncryptionAlgorithm)...)
+		}
+		if posture.CollectPublicAccessBlock {
+			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_public_block"], prometheus.GaugeValue, boolToFloat(bucket.Posture.PublicAccessBlocked), bucketLabelValues...)
 		}
-		ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_duration"], prometheus.GaugeValue, float64(bucket.ListDuration.Seconds()), c.s3Endpoint, c.s3Region, bucket.BucketName)
+		if posture.CollectReplication {
+			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_replication"], prometheus.GaugeValue, boolToFloat(bucket.Posture.ReplicationConfigured), append(append([]string{}, bucketLabelValues...), bucket.Posture.ReplicationDestRegion)...)
+		}
+		if posture.CollectLifecycleRules {
+			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_lifecycle_rules"], prometheus.GaugeValue, float64(bucket.Posture.LifecycleRuleCount), bucketLabelValues...)
+		}
+		if posture.CollectObjectLock {
+			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_object_lock"], prometheus.GaugeValue, boolToFloat(bucket.Posture.ObjectLockEnabled), bucketLabelValues...)
+		}
+		if posture.CollectIntelligentTiering {
+			ch <- prometheus.MustNewConstMetric(metricsDesc["bucket_intelligent_tiering"], prometheus.GaugeValue, float64(bucket.Posture.IntelligentTieringConfigCount), bucketLabelValues...)
+		}
+	}
+}
+
+// boolToFloat converts a bool to the 1/0 convention prometheus gauges use for
+// boolean signals.
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
 	}
+	return 0
 }
 
 // UpdateMetrics updates the cached metrics
 func (c *S3Collector) UpdateMetrics(s3Conn S3Conn, s3BucketNames string) {
-	metrics, err := S3UsageInfo(s3Conn, s3BucketNames)
+	metrics, err := S3UsageInfo(s3Conn, s3BucketNames, c.s3Target)
 
 	c.metricsMutex.Lock()
 	c.Metrics = metrics
 	c.Err = err
+	c.posture = s3Conn.Posture
 	c.metricsMutex.Unlock()
 }
 
@@ -175,43 +299,59 @@ func distinct(input []string) []string {
 	return result
 }
 
-// S3UsageInfo - gets S3 connection details and returns S3Summary
-func S3UsageInfo(s3Conn S3Conn, s3BucketNames string) (S3Summary, error) {
+// S3UsageInfo - gets S3 connection details and returns S3Summary. targetName
+// scopes the lookup of any inventory/events/credential-profile config
+// registered for this target, so two targets can reuse the same bucket or
+// profile name without their state colliding; pass "" for the single-target
+// (non --config.file) setup.
+func S3UsageInfo(s3Conn S3Conn, s3BucketNames, targetName string) (S3Summary, error) {
 	summary := S3Summary{EndpointStatus: false}
 
 	if s3Conn.AWSConfig == nil {
 		return summary, errors.New("AWSConfig is required")
 	}
 
-	client, err := GetS3Client(s3Conn)
+	client, err := GetS3Client(s3Conn, targetName)
 	if err != nil {
 		return summary, err
 	}
-	return fetchBucketData(s3BucketNames, client, s3Conn.Region, summary)
+	return fetchBucketData(targetName, s3BucketNames, client, s3Conn, summary)
 }
 
-func fetchBucketData(s3BucketNames string, s3Client S3ClientInterface, s3Region string, summary S3Summary) (S3Summary, error) {
+func fetchBucketData(targetName, s3BucketNames string, s3Client S3ClientInterface, s3Conn S3Conn, summary S3Summary) (S3Summary, error) {
 	var bucketNames []string
 	start := time.Now()
+	s3Region := s3Conn.Region
+	regionCache := newRegionClientCache(targetName)
 
 	if s3BucketNames != "" {
 		// If specific buckets are provided, use them
 		bucketNames = distinct(strings.Split(s3BucketNames, ","))
 	} else {
-		// Otherwise, fetch all buckets
+		// Otherwise, discover all buckets and apply the configured
+		// include/exclude/deny/tag filters
 		result, err := s3Client.ListBuckets(context.TODO(), &s3.ListBucketsInput{BucketRegion: aws.String(s3Region)})
 		if err != nil {
 			log.Errorf("Failed to list buckets: %v", err)
 			return summary, errors.New("unable to connect to S3 endpoint")
 		}
 
+		var discovered []string
 		for _, b := range result.Buckets {
-			bucketNames = append(bucketNames, aws.ToString(b.Name))
+			discovered = append(discovered, aws.ToString(b.Name))
+		}
+
+		bucketNames, err = filterDiscoveredBuckets(context.TODO(), s3Client, discovered, s3Conn.Discovery)
+		if err != nil {
+			log.Errorf("Failed to filter discovered buckets: %v", err)
+			return summary, err
 		}
 	}
 
 	log.Debugf("List of buckets in %s region: %v", s3Region, bucketNames)
 
+	ResetObjectMetricsForTarget(targetName)
+
 	var wg sync.WaitGroup
 	var summaryMutex sync.Mutex
 
@@ -242,12 +382,33 @@ func fetchBucketData(s3BucketNames string, s3Client S3ClientInterface, s3Region
 		if bucketName == "" {
 			continue
 		}
+		bucketName, profile := splitBucketProfile(bucketName)
 
 		wg.Add(1)
-		go func(bucketName string) {
+		go func(bucketName, profile string) {
 			defer wg.Done()
 
-			storageClasses, duration, err := calculateBucketMetrics(bucketName, s3Client)
+			baseClient := s3Client
+			baseAWSConfig := s3Conn.AWSConfig
+			baseForcePathStyle := s3Conn.ForcePathStyle
+			if profile != "" {
+				if pc, ok := clientForProfile(targetName, profile); ok {
+					baseClient = pc.client
+					baseAWSConfig = pc.awsConfig
+					baseForcePathStyle = pc.forcePathStyle
+				} else {
+					log.Errorf("No client configured for credential profile %q (bucket %s), using the target's default credentials", profile, bucketName)
+				}
+			}
+
+			bucketRegion, err := discoverBucketRegion(context.TODO(), baseClient, bucketName)
+			if err != nil {
+				log.Debugf("Could not discover region for bucket %s, defaulting to %s: %v", bucketName, s3Region, err)
+				bucketRegion = s3Region
+			}
+			bucketClient := regionCache.clientFor(bucketRegion, baseAWSConfig, baseForcePathStyle, baseClient)
+
+			storageClasses, duration, apiCalls, err := computeBucketMetrics(targetName, bucketName, bucketClient, s3Conn.Discovery.ObjectPrefix, s3Conn.Listing)
 			if err != nil {
 				errMutex.Lock()
 				errs = append(errs, err)
@@ -257,13 +418,17 @@ func fetchBucketData(s3BucketNames string, s3Client S3ClientInterface, s3Region
 
 			bucket := Bucket{
 				BucketName:     bucketName,
+				BucketRegion:   bucketRegion,
+				Tags:           fetchBucketTags(context.TODO(), bucketClient, bucketName),
 				StorageClasses: storageClasses,
 				ListDuration:   duration,
+				ListAPICalls:   apiCalls,
+				Posture:        fetchBucketPosture(context.TODO(), bucketClient, bucketName, s3Conn.Posture),
 			}
 
 			processBucketResult(bucket)
 			log.Debugf("Finish bucket %s processing", bucketName)
-		}(bucketName)
+		}(bucketName, profile)
 	}
 
 	wg.Wait()
@@ -280,21 +445,32 @@ func fetchBucketData(s3BucketNames string, s3Client S3ClientInterface, s3Region
 	return summary, nil
 }
 
-// calculateBucketMetrics - computes the total size and object count for a bucket
-func calculateBucketMetrics(bucketName string, s3Client S3ClientInterface) (map[string]StorageClassMetrics, time.Duration, error) {
+// calculateBucketMetrics - computes the total size and object count for a
+// bucket, optionally scoped to a sub-path via objectPrefix. apiCalls counts
+// the ListObjectsV2 pages fetched, so operators can see how many API calls a
+// scrape costs when tuning ListingConfig.
+func calculateBucketMetrics(targetName, bucketName string, s3Client S3ClientInterface, objectPrefix string) (map[string]StorageClassMetrics, time.Duration, int, error) {
 	var continuationToken *string
 	storageClasses := make(map[string]StorageClassMetrics)
+	apiCalls := 0
 
 	start := time.Now()
 
+	var prefix *string
+	if objectPrefix != "" {
+		prefix = aws.String(objectPrefix)
+	}
+
 	for {
 		page, err := s3Client.ListObjectsV2(context.TODO(), &s3.ListObjectsV2Input{
 			Bucket:            aws.String(bucketName),
+			Prefix:            prefix,
 			ContinuationToken: continuationToken,
 		})
+		apiCalls++
 		if err != nil {
 			log.Errorf("Failed to list objects for bucket %s: %v", bucketName, err)
-			return nil, 0, err
+			return nil, 0, apiCalls, err
 		}
 
 		for _, obj := range page.Contents {
@@ -307,14 +483,16 @@ func calculateBucketMetrics(bucketName string, s3Client S3ClientInterface) (map[
 			metrics.Size += float64(*obj.Size)
 			metrics.ObjectNumber++
 			storageClasses[storageClass] = metrics
+
+			observeObjectMetrics(targetName, bucketName, storageClass, float64(*obj.Size), obj.LastModified)
 		}
 
-		if page.IsTruncated != nil && !*page.IsTruncated {
+		if !aws.ToBool(page.IsTruncated) {
 			break
 		}
 		continuationToken = page.NextContinuationToken
 	}
 
 	duration := time.Since(start)
-	return storageClasses, duration, nil
+	return storageClasses, duration, apiCalls, nil
 }
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	log "github.com/sirupsen/logrus"
+)
+
+// ListingConfig controls the parallel prefix-sharded listing strategy used
+// for buckets too large to finish a single serial ListObjectsV2 pagination
+// within the scrape interval.
+type ListingConfig struct {
+	// Workers bounds how many shards are listed concurrently. 0 or 1 keeps
+	// the original serial calculateBucketMetrics behavior regardless of
+	// PrefixShards.
+	Workers int
+
+	// PrefixShards explicitly partitions the bucket's key space. When empty
+	// and Workers > 1, shards are auto-derived from a delimited listing at
+	// "/" instead.
+	PrefixShards []string
+}
+
+// calculateBucketMetricsSharded partitions bucketName's key space into
+// cfg.PrefixShards (or, when unset, prefixes auto-derived from a delimited
+// "/" listing under objectPrefix) and lists each shard concurrently across
+// cfg.Workers goroutines, aggregating size/count under a lock. When no shards
+// can be derived (a flat namespace with no "/" delimiters can't be
+// partitioned this way), it returns the root-level objects the delimited
+// listing already walked, without a further listing pass.
+func calculateBucketMetricsSharded(targetName, bucketName string, s3Client S3ClientInterface, objectPrefix string, cfg ListingConfig) (map[string]StorageClassMetrics, time.Duration, int, error) {
+	start := time.Now()
+
+	shards := cfg.PrefixShards
+	derivedAPICalls := 0
+	rootMetrics := map[string]StorageClassMetrics(nil)
+	if len(shards) == 0 {
+		var err error
+		shards, rootMetrics, derivedAPICalls, err = deriveBucketPrefixShards(targetName, bucketName, s3Client, objectPrefix)
+		if err != nil {
+			return nil, 0, derivedAPICalls, err
+		}
+	}
+
+	if len(shards) == 0 {
+		// No CommonPrefixes under objectPrefix means the bucket's key space is
+		// flat, so the delimited listing that derived shards already walked
+		// every object into rootMetrics - nothing left to shard-list.
+		return rootMetrics, time.Since(start), derivedAPICalls, nil
+	}
+
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(shards) {
+		workers = len(shards)
+	}
+
+	storageClasses := make(map[string]StorageClassMetrics)
+	for class, metrics := range rootMetrics {
+		storageClasses[class] = metrics
+	}
+	var mu sync.Mutex
+	apiCalls := int64(derivedAPICalls)
+	var firstErr error
+	var errOnce sync.Once
+
+	shardCh := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for shardPrefix := range shardCh {
+				classes, calls, err := listBucketShard(targetName, bucketName, s3Client, shardPrefix)
+				atomic.AddInt64(&apiCalls, int64(calls))
+				if err != nil {
+					errOnce.Do(func() { firstErr = err })
+					continue
+				}
+
+				mu.Lock()
+				for class, metrics := range classes {
+					total := storageClasses[class]
+					total.Size += metrics.Size
+					total.ObjectNumber += metrics.ObjectNumber
+					storageClasses[class] = total
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, shardPrefix := range shards {
+		shardCh <- shardPrefix
+	}
+	close(shardCh)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, 0, int(apiCalls), firstErr
+	}
+
+	log.Debugf("Bucket %s: listed %d prefix shards across %d workers", bucketName, len(shards), workers)
+	return storageClasses, time.Since(start), int(apiCalls), nil
+}
+
+// deriveBucketPrefixShards auto-derives shard prefixes for bucketName by
+// paginating a delimited listing at "/" under objectPrefix and using every
+// returned CommonPrefix as a shard. Objects sitting directly at the root
+// (not under any "/"-delimited prefix) come back as page.Contents rather
+// than a CommonPrefix, so they're aggregated into rootMetrics instead of
+// being dropped. A flat (non "/"-delimited) key layout yields no shards,
+// since rootMetrics alone already accounts for the whole bucket in that
+// case - signaling the caller to skip further shard listing.
+func deriveBucketPrefixShards(targetName, bucketName string, s3Client S3ClientInterface, objectPrefix string) ([]string, map[string]StorageClassMetrics, int, error) {
+	var prefix *string
+	if objectPrefix != "" {
+		prefix = aws.String(objectPrefix)
+	}
+
+	var shards []string
+	rootMetrics := make(map[string]StorageClassMetrics)
+	var continuationToken *string
+	apiCalls := 0
+
+	for {
+		page, err := s3Client.ListObjectsV2(context.TODO(), &s3.ListObjectsV2Input{
+			Bucket:            aws.String(bucketName),
+			Prefix:            prefix,
+			Delimiter:         aws.String("/"),
+			ContinuationToken: continuationToken,
+		})
+		apiCalls++
+		if err != nil {
+			return nil, nil, apiCalls, err
+		}
+
+		for _, cp := range page.CommonPrefixes {
+			shards = append(shards, aws.ToString(cp.Prefix))
+		}
+
+		for _, obj := range page.Contents {
+			storageClass := string(obj.StorageClass)
+			if storageClass == "" {
+				storageClass = "STANDARD"
+			}
+
+			metrics := rootMetrics[storageClass]
+			metrics.Size += float64(*obj.Size)
+			metrics.ObjectNumber++
+			rootMetrics[storageClass] = metrics
+
+			observeObjectMetrics(targetName, bucketName, storageClass, float64(*obj.Size), obj.LastModified)
+		}
+
+		if !aws.ToBool(page.IsTruncated) {
+			break
+		}
+		continuationToken = page.NextContinuationToken
+	}
+
+	return shards, rootMetrics, apiCalls, nil
+}
+
+// listBucketShard lists every object under shardPrefix in bucketName, the
+// unit of work handed to each worker in calculateBucketMetricsSharded.
+func listBucketShard(targetName, bucketName string, s3Client S3ClientInterface, shardPrefix string) (map[string]StorageClassMetrics, int, error) {
+	storageClasses := make(map[string]StorageClassMetrics)
+	var continuationToken *string
+	apiCalls := 0
+
+	for {
+		page, err := s3Client.ListObjectsV2(context.TODO(), &s3.ListObjectsV2Input{
+			Bucket:            aws.String(bucketName),
+			Prefix:            aws.String(shardPrefix),
+			ContinuationToken: continuationToken,
+		})
+		apiCalls++
+		if err != nil {
+			log.Errorf("Failed to list shard %q for bucket %s: %v", shardPrefix, bucketName, err)
+			return nil, apiCalls, err
+		}
+
+		for _, obj := range page.Contents {
+			storageClass := string(obj.StorageClass)
+			if storageClass == "" {
+				storageClass = "STANDARD"
+			}
+
+			metrics := storageClasses[storageClass]
+			metrics.Size += float64(*obj.Size)
+			metrics.ObjectNumber++
+			storageClasses[storageClass] = metrics
+
+			observeObjectMetrics(targetName, bucketName, storageClass, float64(*obj.Size), obj.LastModified)
+		}
+
+		if !aws.ToBool(page.IsTruncated) {
+			break
+		}
+		continuationToken = page.NextContinuationToken
+	}
+
+	return storageClasses, apiCalls, nil
+}
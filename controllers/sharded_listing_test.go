@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateBucketMetricsSharded_AutoDerivedPrefixes(t *testing.T) {
+	server := newFakeS3Server(t)
+	client := newFakeS3Client(t, server)
+
+	// gofakes3/s3mem doesn't persist PutObjectInput.StorageClass (it always
+	// reports objects back as STANDARD through ListObjectsV2), so this only
+	// exercises auto-derived prefix sharding across multiple top-level
+	// prefixes, not storage-class differentiation.
+	client.seedBucket(t, "sharded-bucket", "standard/", 3, 1024, types.StorageClassStandard)
+	client.seedBucket(t, "sharded-bucket", "glacier/", 2, 4096, types.StorageClassStandard)
+
+	storageClasses, duration, apiCalls, err := calculateBucketMetricsSharded("target-a", "sharded-bucket", client, "", ListingConfig{Workers: 4})
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(3*1024+2*4096), storageClasses["STANDARD"].Size)
+	assert.Equal(t, float64(5), storageClasses["STANDARD"].ObjectNumber)
+	assert.GreaterOrEqual(t, duration.Nanoseconds(), int64(0))
+	assert.Greater(t, apiCalls, 0)
+}
+
+func TestCalculateBucketMetricsSharded_ExplicitPrefixShards(t *testing.T) {
+	server := newFakeS3Server(t)
+	client := newFakeS3Client(t, server)
+
+	client.seedBucket(t, "sharded-bucket2", "a/", 2, 100, types.StorageClassStandard)
+	client.seedBucket(t, "sharded-bucket2", "b/", 3, 100, types.StorageClassStandard)
+
+	storageClasses, _, _, err := calculateBucketMetricsSharded("target-a", "sharded-bucket2", client, "", ListingConfig{
+		Workers:      2,
+		PrefixShards: []string{"a/", "b/"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), storageClasses["STANDARD"].ObjectNumber)
+}
+
+func TestCalculateBucketMetricsSharded_FlatNamespaceCountsRootObjects(t *testing.T) {
+	server := newFakeS3Server(t)
+	client := newFakeS3Client(t, server)
+
+	client.seedBucket(t, "flat-bucket", "object-", 2, 100, types.StorageClassStandard)
+
+	storageClasses, _, _, err := calculateBucketMetricsSharded("target-a", "flat-bucket", client, "", ListingConfig{Workers: 4})
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), storageClasses["STANDARD"].ObjectNumber)
+}
+
+func TestCalculateBucketMetricsSharded_RootObjectsCountedAlongsideShards(t *testing.T) {
+	server := newFakeS3Server(t)
+	client := newFakeS3Client(t, server)
+
+	// Objects sitting directly at the bucket root (no "/" in their key) come
+	// back as page.Contents rather than a CommonPrefix, so they must still be
+	// counted even though "nested/" is the only derived shard.
+	client.seedBucket(t, "mixed-bucket", "root-object-", 2, 100, types.StorageClassStandard)
+	client.seedBucket(t, "mixed-bucket", "nested/", 3, 200, types.StorageClassStandard)
+
+	storageClasses, _, _, err := calculateBucketMetricsSharded("target-a", "mixed-bucket", client, "", ListingConfig{Workers: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), storageClasses["STANDARD"].ObjectNumber)
+	assert.Equal(t, float64(2*100+3*200), storageClasses["STANDARD"].Size)
+}
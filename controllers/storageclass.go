@@ -0,0 +1,35 @@
+package controllers
+
+import "sync"
+
+var (
+	storageClassBreakdownMutex   sync.RWMutex
+	collectStorageClassBreakdown bool
+)
+
+// SetStorageClassBreakdown enables or disables the s3_bucket_size_bytes_by_class
+// and s3_bucket_objects_by_class metrics. These duplicate the "storageClass"
+// label already on s3_bucket_size/s3_bucket_object_number under explicit,
+// cost-modeling-oriented metric names, so they stay opt-in rather than
+// always emitted alongside the existing per-class metrics. It must be called
+// before the first scrape, since prometheus.Desc label sets are fixed at
+// construction time.
+func SetStorageClassBreakdown(enabled bool) {
+	storageClassBreakdownMutex.Lock()
+	collectStorageClassBreakdown = enabled
+	storageClassBreakdownMutex.Unlock()
+
+	metricsDesc = buildMetricsDesc()
+}
+
+// ResetStorageClassBreakdown disables the storage-class breakdown metrics (used by tests).
+func ResetStorageClassBreakdown() {
+	SetStorageClassBreakdown(false)
+}
+
+// storageClassBreakdownEnabled reports whether the by-class metrics should be emitted.
+func storageClassBreakdownEnabled() bool {
+	storageClassBreakdownMutex.RLock()
+	defer storageClassBreakdownMutex.RUnlock()
+	return collectStorageClassBreakdown
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageClassBreakdownEnabled_DefaultsToFalse(t *testing.T) {
+	ResetStorageClassBreakdown()
+
+	assert.False(t, storageClassBreakdownEnabled())
+	assert.NotContains(t, metricsDesc, "bucket_size_by_class")
+	assert.NotContains(t, metricsDesc, "bucket_objects_by_class")
+}
+
+func TestSetStorageClassBreakdown_AddsDescs(t *testing.T) {
+	SetStorageClassBreakdown(true)
+	defer ResetStorageClassBreakdown()
+
+	assert.True(t, storageClassBreakdownEnabled())
+	assert.Contains(t, metricsDesc, "bucket_size_by_class")
+	assert.Contains(t, metricsDesc, "bucket_objects_by_class")
+	assert.Contains(t, metricsDesc["bucket_size_by_class"].String(), "s3_bucket_size_bytes_by_class")
+	assert.Contains(t, metricsDesc["bucket_objects_by_class"].String(), "s3_bucket_objects_by_class")
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	bucketTagLabelsMutex           sync.RWMutex
+	bucketTagLabelKeys             []string
+	attachTagLabelsToBucketMetrics bool
+)
+
+// SetBucketTagLabels configures the allowlist of S3 bucket-tag keys exposed
+// as Prometheus labels on s3_bucket_info, and, when attachToBucketMetrics is
+// set, on s3_bucket_size/s3_bucket_object_number/s3_list_duration_seconds as
+// well. It must be called before the first scrape, since prometheus.Desc
+// label sets are fixed at construction time.
+func SetBucketTagLabels(keys []string, attachToBucketMetrics bool) {
+	bucketTagLabelsMutex.Lock()
+	bucketTagLabelKeys = keys
+	attachTagLabelsToBucketMetrics = attachToBucketMetrics
+	bucketTagLabelsMutex.Unlock()
+
+	metricsDesc = buildMetricsDesc()
+}
+
+// ResetBucketTagLabels clears the configured tag-label allowlist (used by tests).
+func ResetBucketTagLabels() {
+	SetBucketTagLabels(nil, false)
+}
+
+// bucketTagLabelValues returns tags's values for the configured allowlist,
+// in the same order as bucketTagLabelKeys, defaulting to "" for any key the
+// bucket wasn't tagged with.
+func bucketTagLabelValues(tags map[string]string) []string {
+	bucketTagLabelsMutex.RLock()
+	keys := bucketTagLabelKeys
+	bucketTagLabelsMutex.RUnlock()
+
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = tags[key]
+	}
+	return values
+}
+
+// fetchBucketTags retrieves bucketName's tag set and filters it down to the
+// configured allowlist. It returns an empty map without calling S3 at all
+// when no allowlist is configured, and swallows GetBucketTagging errors
+// (e.g. a bucket with no tags returns NoSuchTagSet) since missing tags
+// should just surface as empty label values rather than fail the scrape.
+func fetchBucketTags(ctx context.Context, s3Client S3ClientInterface, bucketName string) map[string]string {
+	bucketTagLabelsMutex.RLock()
+	keys := bucketTagLabelKeys
+	bucketTagLabelsMutex.RUnlock()
+
+	tags := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return tags
+	}
+
+	out, err := s3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: aws.String(bucketName)})
+	if err != nil {
+		log.Debugf("Could not fetch tags for bucket %s, labeling with empty values: %v", bucketName, err)
+		return tags
+	}
+
+	allowed := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		allowed[key] = struct{}{}
+	}
+
+	for _, tag := range out.TagSet {
+		key := aws.ToString(tag.Key)
+		if _, ok := allowed[key]; ok {
+			tags[key] = aws.ToString(tag.Value)
+		}
+	}
+
+	return tags
+}
+
+// fetchAllBucketTags retrieves bucketName's full tag set, unfiltered by the
+// bucket-tag-label allowlist. It is used by bucket discovery's tag selector,
+// which needs to evaluate tags that aren't necessarily exposed as metric
+// labels.
+func fetchAllBucketTags(ctx context.Context, s3Client S3ClientInterface, bucketName string) map[string]string {
+	out, err := s3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: aws.String(bucketName)})
+	if err != nil {
+		log.Debugf("Could not fetch tags for bucket %s, excluding it from tag-selector discovery: %v", bucketName, err)
+		return nil
+	}
+
+	tags := make(map[string]string, len(out.TagSet))
+	for _, tag := range out.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags
+}
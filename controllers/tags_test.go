@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchBucketTags_NoAllowlistSkipsAPICall(t *testing.T) {
+	ResetBucketTagLabels()
+
+	mockClient := new(MockS3Client)
+
+	tags := fetchBucketTags(context.Background(), mockClient, "bucket1")
+
+	assert.Empty(t, tags)
+	mockClient.AssertNotCalled(t, "GetBucketTagging", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFetchBucketTags_FiltersToAllowlist(t *testing.T) {
+	SetBucketTagLabels([]string{"team", "env"}, false)
+	defer ResetBucketTagLabels()
+
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketTagging", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetBucketTaggingOutput{
+		TagSet: []types.Tag{
+			{Key: aws.String("team"), Value: aws.String("payments")},
+			{Key: aws.String("owner"), Value: aws.String("alice")},
+		},
+	}, nil)
+
+	tags := fetchBucketTags(context.Background(), mockClient, "bucket1")
+
+	require.Equal(t, "payments", tags["team"])
+	assert.NotContains(t, tags, "owner")
+	assert.NotContains(t, tags, "env")
+}
+
+func TestFetchBucketTags_ErrorYieldsEmptyMap(t *testing.T) {
+	SetBucketTagLabels([]string{"team"}, false)
+	defer ResetBucketTagLabels()
+
+	mockClient := new(MockS3Client)
+	mockClient.On("GetBucketTagging", mock.Anything, mock.Anything, mock.Anything).Return(
+		(*s3.GetBucketTaggingOutput)(nil), assert.AnError,
+	)
+
+	tags := fetchBucketTags(context.Background(), mockClient, "bucket1")
+
+	assert.Empty(t, tags)
+}
+
+func TestBucketTagLabelValues_DefaultsMissingKeysToEmptyString(t *testing.T) {
+	SetBucketTagLabels([]string{"team", "env"}, false)
+	defer ResetBucketTagLabels()
+
+	values := bucketTagLabelValues(map[string]string{"team": "payments"})
+
+	assert.Equal(t, []string{"payments", ""}, values)
+}
+
+func TestSetBucketTagLabels_AttachToBucketMetricsExtendsDescs(t *testing.T) {
+	SetBucketTagLabels([]string{"team"}, true)
+	defer ResetBucketTagLabels()
+
+	assert.Contains(t, metricsDesc["bucket_size"].String(), "team")
+	assert.Contains(t, metricsDesc["bucket_info"].String(), "team")
+}
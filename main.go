@@ -5,36 +5,72 @@ import (
 	"flag"
 	"net/http"
 	"os"
-	"reflect"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 	"github.com/tropnikovvl/s3-bucket-exporter/auth"
+	"github.com/tropnikovvl/s3-bucket-exporter/config"
 	"github.com/tropnikovvl/s3-bucket-exporter/controllers"
+	"github.com/tropnikovvl/s3-bucket-exporter/storage"
 )
 
 var (
-	up = prometheus.NewDesc("s3_endpoint_up", "Connection to S3 successful", []string{"s3Endpoint", "s3Region"}, nil)
-
-	listenPort       string
-	logLevel         string
-	logFormat        string
-	scrapeInterval   string
-	s3Endpoint       string
-	s3BucketNames    string
-	s3AccessKey      string
-	s3SecretKey      string
-	s3Region         string
-	s3ForcePathStyle bool
-	s3SkipTLSVerify  bool
-
-	metricsMutex  sync.RWMutex
-	cachedMetrics controllers.S3Summary
-	cachedError   error
+	configFile              string
+	listenPort              string
+	logLevel                string
+	logFormat               string
+	scrapeInterval          string
+	s3Endpoint              string
+	s3BucketNames           string
+	s3AccessKey             string
+	s3SecretKey             string
+	s3Region                string
+	s3ForcePathStyle        bool
+	s3SkipTLSVerify         bool
+	s3ObjectAgeBuckets      string
+	s3ObjectSizeBuckets     string
+	s3BucketTagLabels       string
+	s3AttachBucketTags      bool
+	s3InventoryMode         bool
+	s3InventoryBucket       string
+	s3InventoryPrefix       string
+	s3InventoryMaxAge       string
+	storageBackend          string
+	s3AuthMethod            string
+	s3RoleARN               string
+	s3RoleSessionName       string
+	s3ExternalID            string
+	s3DurationSeconds       int
+	s3WebIdentity           string
+	s3BucketDenyNames       string
+	s3BucketIncludeRgx      string
+	s3BucketExcludeRgx      string
+	s3BucketTagSelector     string
+	s3ObjectPrefix          string
+	s3CollectVersioning     bool
+	s3CollectEncryption     bool
+	s3CollectPublicBlock    bool
+	s3CollectReplication    bool
+	s3CollectLifecycle      bool
+	s3CollectObjectLock     bool
+	s3EventsSQSURL          string
+	s3EventsRegion          string
+	s3FullReconcileInterval string
+	s3ListWorkers           int
+	s3ListPrefixShards      string
+	s3CollectIntelTiering   bool
+	s3CollectClassBreakdown bool
+
+	// s3Collector is the single-target (non --config.file) collector, built
+	// once in main() after flags are parsed so it can pick up s3Endpoint/
+	// s3Region, and shared between updateMetrics (the writer) and the
+	// registered prometheus.Collector (the reader) exactly like each
+	// targetRunner shares its own controllers.S3Collector in multitarget.go.
+	s3Collector *controllers.S3Collector
 )
 
 func envString(key, def string) string {
@@ -52,7 +88,32 @@ func envBool(key string, def bool) bool {
 	return def2
 }
 
+func envInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// splitNonEmpty splits a comma-separated flag value into its trimmed,
+// non-empty parts, returning nil for an empty input instead of []string{""}.
+func splitNonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	var parts []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
 func initFlags() {
+	flag.StringVar(&configFile, "config.file", envString("CONFIG_FILE", ""), "CONFIG_FILE - path to a YAML file listing multiple scrape targets; when set it takes precedence over the single-target flags below")
 	flag.StringVar(&s3Endpoint, "s3_endpoint", envString("S3_ENDPOINT", ""), "S3_ENDPOINT - eg. myceph.com:7480")
 	flag.StringVar(&s3AccessKey, "s3_access_key", envString("S3_ACCESS_KEY", ""), "S3_ACCESS_KEY - aws_access_key")
 	flag.StringVar(&s3SecretKey, "s3_secret_key", envString("S3_SECRET_KEY", ""), "S3_SECRET_KEY - aws_secret_key")
@@ -64,68 +125,150 @@ func initFlags() {
 	flag.StringVar(&scrapeInterval, "scrape_interval", envString("SCRAPE_INTERVAL", "5m"), "SCRAPE_INTERVAL - eg. 30s, 5m, 1h")
 	flag.BoolVar(&s3ForcePathStyle, "s3_force_path_style", envBool("S3_FORCE_PATH_STYLE", false), "S3_FORCE_PATH_STYLE")
 	flag.BoolVar(&s3SkipTLSVerify, "s3_skip_tls_verify", envBool("S3_SKIP_TLS_VERIFY", false), "S3_SKIP_TLS_VERIFY - Skip TLS certificate verification")
+	flag.StringVar(&s3ObjectAgeBuckets, "s3_object_age_buckets", envString("S3_OBJECT_AGE_BUCKETS", ""), "S3_OBJECT_AGE_BUCKETS - comma-separated object-age histogram bucket boundaries in seconds, eg. '2592000,7776000,31536000'")
+	flag.StringVar(&s3ObjectSizeBuckets, "s3_object_size_buckets", envString("S3_OBJECT_SIZE_BUCKETS", ""), "S3_OBJECT_SIZE_BUCKETS - comma-separated object-size histogram bucket boundaries in bytes")
+	flag.StringVar(&s3BucketTagLabels, "s3_bucket_tag_labels", envString("S3_BUCKET_TAG_LABELS", ""), "S3_BUCKET_TAG_LABELS - comma-separated allowlist of bucket-tag keys to expose as labels on s3_bucket_info, eg. 'team,env,cost_center'")
+	flag.BoolVar(&s3AttachBucketTags, "s3_attach_bucket_tags_to_metrics", envBool("S3_ATTACH_BUCKET_TAGS_TO_METRICS", false), "S3_ATTACH_BUCKET_TAGS_TO_METRICS - also attach the S3_BUCKET_TAG_LABELS labels to s3_bucket_size/s3_bucket_object_number/s3_list_duration_seconds")
+	flag.BoolVar(&s3InventoryMode, "s3_inventory_mode", envBool("S3_INVENTORY_MODE", false), "S3_INVENTORY_MODE - read bucket metrics from the most recent S3 Inventory report instead of a live ListObjectsV2 walk, for buckets named in S3_BUCKET_NAMES")
+	flag.StringVar(&s3InventoryBucket, "s3_inventory_bucket", envString("S3_INVENTORY_BUCKET", ""), "S3_INVENTORY_BUCKET - bucket holding the S3 Inventory reports")
+	flag.StringVar(&s3InventoryPrefix, "s3_inventory_prefix", envString("S3_INVENTORY_PREFIX", ""), "S3_INVENTORY_PREFIX - prefix the S3 Inventory reports are written under in S3_INVENTORY_BUCKET")
+	flag.StringVar(&s3InventoryMaxAge, "s3_inventory_max_age", envString("S3_INVENTORY_MAX_AGE", ""), "S3_INVENTORY_MAX_AGE - eg. 24h; fall back to ListObjectsV2 if the newest inventory report is older than this")
+	flag.StringVar(&storageBackend, "storage_backend", envString("STORAGE_BACKEND", ""), "STORAGE_BACKEND - object storage provider, currently only S3 (the default) is implemented")
+	flag.StringVar(&s3AuthMethod, "s3_auth_method", envString("S3_AUTH_METHOD", ""), "S3_AUTH_METHOD - 'static', 'role' or 'web_identity'; auto-detected from the other s3_* flags when empty")
+	flag.StringVar(&s3RoleARN, "s3_role_arn", envString("S3_ROLE_ARN", ""), "S3_ROLE_ARN - IAM role to assume before talking to S3")
+	flag.StringVar(&s3RoleSessionName, "s3_role_session_name", envString("S3_ROLE_SESSION_NAME", ""), "S3_ROLE_SESSION_NAME - session name to use when assuming S3_ROLE_ARN")
+	flag.StringVar(&s3ExternalID, "s3_external_id", envString("S3_EXTERNAL_ID", ""), "S3_EXTERNAL_ID - external ID to pass when assuming S3_ROLE_ARN")
+	flag.IntVar(&s3DurationSeconds, "s3_duration_seconds", envInt("S3_DURATION_SECONDS", 0), "S3_DURATION_SECONDS - assumed role session duration in seconds; defaults to the AWS SDK's own default when 0")
+	flag.StringVar(&s3WebIdentity, "s3_web_identity_token_file", envString("S3_WEB_IDENTITY_TOKEN_FILE", ""), "S3_WEB_IDENTITY_TOKEN_FILE - path to an EKS IRSA/OIDC web identity token file")
+	flag.StringVar(&s3BucketDenyNames, "s3_bucket_deny_names", envString("S3_BUCKET_DENY_NAMES", ""), "S3_BUCKET_DENY_NAMES - comma-separated bucket names to always skip during auto-discovery (ignored when S3_BUCKET_NAMES is set)")
+	flag.StringVar(&s3BucketIncludeRgx, "s3_bucket_include_regex", envString("S3_BUCKET_INCLUDE_REGEX", ""), "S3_BUCKET_INCLUDE_REGEX - only auto-discover buckets whose name matches this regex")
+	flag.StringVar(&s3BucketExcludeRgx, "s3_bucket_exclude_regex", envString("S3_BUCKET_EXCLUDE_REGEX", ""), "S3_BUCKET_EXCLUDE_REGEX - skip auto-discovered buckets whose name matches this regex")
+	flag.StringVar(&s3BucketTagSelector, "s3_bucket_tag_selector", envString("S3_BUCKET_TAG_SELECTOR", ""), "S3_BUCKET_TAG_SELECTOR - 'key=value'; only auto-discover buckets carrying a matching tag")
+	flag.StringVar(&s3ObjectPrefix, "s3_object_prefix", envString("S3_OBJECT_PREFIX", ""), "S3_OBJECT_PREFIX - scope object listing to this sub-path of each bucket instead of the whole bucket")
+	flag.BoolVar(&s3CollectVersioning, "s3_collect_versioning", envBool("S3_COLLECT_VERSIONING", false), "S3_COLLECT_VERSIONING - expose s3_bucket_versioning_enabled, costs one extra GetBucketVersioning call per bucket per scrape")
+	flag.BoolVar(&s3CollectEncryption, "s3_collect_encryption", envBool("S3_COLLECT_ENCRYPTION", false), "S3_COLLECT_ENCRYPTION - expose s3_bucket_encryption_enabled, costs one extra GetBucketEncryption call per bucket per scrape")
+	flag.BoolVar(&s3CollectPublicBlock, "s3_collect_public_access_block", envBool("S3_COLLECT_PUBLIC_ACCESS_BLOCK", false), "S3_COLLECT_PUBLIC_ACCESS_BLOCK - expose s3_bucket_public_access_block, costs one extra GetPublicAccessBlock call per bucket per scrape")
+	flag.BoolVar(&s3CollectReplication, "s3_collect_replication", envBool("S3_COLLECT_REPLICATION", false), "S3_COLLECT_REPLICATION - expose s3_bucket_replication_configured, costs one extra GetBucketReplication call per bucket per scrape")
+	flag.BoolVar(&s3CollectLifecycle, "s3_collect_lifecycle_rules", envBool("S3_COLLECT_LIFECYCLE_RULES", false), "S3_COLLECT_LIFECYCLE_RULES - expose s3_bucket_lifecycle_rules, costs one extra GetBucketLifecycleConfiguration call per bucket per scrape")
+	flag.BoolVar(&s3CollectObjectLock, "s3_collect_object_lock", envBool("S3_COLLECT_OBJECT_LOCK", false), "S3_COLLECT_OBJECT_LOCK - expose s3_bucket_object_lock_enabled, costs one extra GetObjectLockConfiguration call per bucket per scrape")
+	flag.StringVar(&s3EventsSQSURL, "s3_events_sqs_url", envString("S3_EVENTS_SQS_URL", ""), "S3_EVENTS_SQS_URL - SQS queue URL fed by S3 Event Notifications; when set, S3_BUCKET_NAMES is maintained from events instead of a periodic ListObjectsV2 walk")
+	flag.StringVar(&s3EventsRegion, "s3_events_region", envString("S3_EVENTS_REGION", ""), "S3_EVENTS_REGION - region of S3_EVENTS_SQS_URL; defaults to S3_REGION when empty")
+	flag.StringVar(&s3FullReconcileInterval, "s3_full_reconcile_interval", envString("S3_FULL_RECONCILE_INTERVAL", "24h"), "S3_FULL_RECONCILE_INTERVAL - how often to force a full ListObjectsV2 walk to correct the event-derived counters, eg. 24h")
+	flag.IntVar(&s3ListWorkers, "s3_list_workers", envInt("S3_LIST_WORKERS", 0), "S3_LIST_WORKERS - number of concurrent prefix-sharded ListObjectsV2 workers per bucket; 0 or 1 lists serially")
+	flag.StringVar(&s3ListPrefixShards, "s3_list_prefix_shards", envString("S3_LIST_PREFIX_SHARDS", ""), "S3_LIST_PREFIX_SHARDS - comma-separated key prefixes to shard listing across; auto-derived from a delimited '/' listing when empty and S3_LIST_WORKERS > 1")
+	flag.BoolVar(&s3CollectIntelTiering, "s3_collect_intelligent_tiering", envBool("S3_COLLECT_INTELLIGENT_TIERING", false), "S3_COLLECT_INTELLIGENT_TIERING - expose s3_bucket_intelligent_tiering_configuration_count, costs extra ListBucketIntelligentTieringConfigurations calls per bucket per scrape")
+	flag.BoolVar(&s3CollectClassBreakdown, "s3_collect_storage_class_breakdown", envBool("S3_COLLECT_STORAGE_CLASS_BREAKDOWN", false), "S3_COLLECT_STORAGE_CLASS_BREAKDOWN - expose s3_bucket_size_bytes_by_class/s3_bucket_objects_by_class alongside the existing per-class metrics")
 }
 
-// S3Collector struct
-type S3Collector struct{}
+// parseBucketBoundaries parses a comma-separated list of float64 bucket
+// boundaries, returning false if raw is empty so callers can keep the
+// package default.
+func parseBucketBoundaries(raw string) ([]float64, bool) {
+	if raw == "" {
+		return nil, false
+	}
 
-// Describe - Implements prometheus.Collector
-func (c S3Collector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- up
+	parts := strings.Split(raw, ",")
+	buckets := make([]float64, 0, len(parts))
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			log.Errorf("Invalid bucket boundary %q, ignoring custom buckets", p)
+			return nil, false
+		}
+		buckets = append(buckets, v)
+	}
+
+	return buckets, true
 }
 
-// Collect - Implements prometheus.Collector.
-func (c S3Collector) Collect(ch chan<- prometheus.Metric) {
-	metricsMutex.RLock()
-	metrics := cachedMetrics
-	err := cachedError
-	metricsMutex.RUnlock()
+// configureInventoryMode registers the buckets in S3_BUCKET_NAMES against
+// the S3 Inventory data source instead of a live ListObjectsV2 walk.
+// Inventory mode requires explicitly named buckets since the manifest path
+// is keyed by source bucket name.
+func configureInventoryMode() {
+	if s3BucketNames == "" {
+		log.Errorf("S3_INVENTORY_MODE is set but S3_BUCKET_NAMES is empty, inventory mode requires explicitly named buckets")
+		return
+	}
 
-	s3Status := 0
-	if metrics.S3Status {
-		s3Status = 1
+	maxAge, err := time.ParseDuration(s3InventoryMaxAge)
+	if err != nil && s3InventoryMaxAge != "" {
+		log.Errorf("Invalid S3_INVENTORY_MAX_AGE %q, ignoring freshness window", s3InventoryMaxAge)
 	}
 
-	if err != nil {
-		ch <- prometheus.MustNewConstMetric(up, prometheus.GaugeValue, float64(s3Status), s3Endpoint, s3Region)
-		log.Errorf("Cached error: %v", err)
+	controllers.ConfigureInventoryForBuckets("", s3BucketNames, controllers.InventoryConfig{
+		Bucket: s3InventoryBucket,
+		Prefix: s3InventoryPrefix,
+		Format: "CSV",
+		MaxAge: maxAge,
+	})
+}
+
+// configureEventsMode starts an SQS-backed event listener for the buckets in
+// S3_BUCKET_NAMES, maintaining in-memory counters reconciled against a full
+// ListObjectsV2 walk every S3_FULL_RECONCILE_INTERVAL. Like inventory mode,
+// it requires explicitly named buckets since the queue carries no way to
+// discover bucket names on its own.
+func configureEventsMode() {
+	if s3BucketNames == "" {
+		log.Errorf("S3_EVENTS_SQS_URL is set but S3_BUCKET_NAMES is empty, events mode requires explicitly named buckets")
 		return
 	}
 
-	ch <- prometheus.MustNewConstMetric(up, prometheus.GaugeValue, float64(s3Status), s3Endpoint, s3Region)
-	log.Debugf("Cached S3 metrics %s: %+v", s3Endpoint, metrics)
-
-	descS := prometheus.NewDesc("s3_total_size", "S3 Total Bucket Size", []string{"s3Endpoint", "s3Region", "storageClass"}, nil)
-	descON := prometheus.NewDesc("s3_total_object_number", "S3 Total Object Number", []string{"s3Endpoint", "s3Region", "storageClass"}, nil)
-	descDS := prometheus.NewDesc("s3_list_total_duration_seconds", "Total time spent listing objects across all buckets", []string{"s3Endpoint", "s3Region"}, nil)
+	reconcileInterval, err := time.ParseDuration(s3FullReconcileInterval)
+	if err != nil {
+		reconcileInterval = 24 * time.Hour
+		log.Errorf("Invalid S3_FULL_RECONCILE_INTERVAL %q, using default %v", s3FullReconcileInterval, reconcileInterval)
+	}
 
-	for class, s3Metrics := range metrics.StorageClasses {
-		ch <- prometheus.MustNewConstMetric(descS, prometheus.GaugeValue, s3Metrics.Size, s3Endpoint, s3Region, class)
-		ch <- prometheus.MustNewConstMetric(descON, prometheus.GaugeValue, s3Metrics.ObjectNumber, s3Endpoint, s3Region, class)
+	region := s3EventsRegion
+	if region == "" {
+		region = s3Region
 	}
-	ch <- prometheus.MustNewConstMetric(descDS, prometheus.GaugeValue, float64(metrics.TotalListDuration.Seconds()), s3Endpoint, s3Region)
 
-	descBucketS := prometheus.NewDesc("s3_bucket_size", "S3 Bucket Size", []string{"s3Endpoint", "s3Region", "bucketName", "storageClass"}, nil)
-	descBucketON := prometheus.NewDesc("s3_bucket_object_number", "S3 Bucket Object Number", []string{"s3Endpoint", "s3Region", "bucketName", "storageClass"}, nil)
+	authCfg := auth.AuthConfig{
+		Method:          s3AuthMethod,
+		Region:          region,
+		Endpoint:        s3Endpoint,
+		AccessKey:       s3AccessKey,
+		SecretKey:       s3SecretKey,
+		RoleARN:         s3RoleARN,
+		RoleSessionName: s3RoleSessionName,
+		ExternalID:      s3ExternalID,
+		DurationSeconds: s3DurationSeconds,
+		WebIdentity:     s3WebIdentity,
+		SkipTLSVerify:   s3SkipTLSVerify,
+	}
+	auth.DetectAuthMethod(&authCfg)
 
-	for _, bucket := range metrics.S3Buckets {
-		for class, s3Metrics := range bucket.StorageClasses {
-			ch <- prometheus.MustNewConstMetric(descBucketS, prometheus.GaugeValue, s3Metrics.Size, s3Endpoint, s3Region, bucket.BucketName, class)
-			ch <- prometheus.MustNewConstMetric(descBucketON, prometheus.GaugeValue, s3Metrics.ObjectNumber, s3Endpoint, s3Region, bucket.BucketName, class)
-		}
-		descBucketDS := prometheus.NewDesc("s3_list_duration_seconds", "Time spent listing objects in bucket", []string{"s3Endpoint", "s3Region", "bucketName"}, nil)
-		ch <- prometheus.MustNewConstMetric(descBucketDS, prometheus.GaugeValue, float64(bucket.ListDuration.Seconds()), s3Endpoint, s3Region, bucket.BucketName)
+	awsCfg, err := auth.NewAWSAuth(authCfg).GetConfig(context.Background())
+	if err != nil {
+		log.Errorf("Failed to configure authentication for event-driven mode: %v", err)
+		return
 	}
+
+	controllers.ConfigureEventsForBuckets(context.Background(), "", s3BucketNames, controllers.EventsConfig{
+		SQSURL:                s3EventsSQSURL,
+		AWSConfig:             &awsCfg,
+		FullReconcileInterval: reconcileInterval,
+	})
 }
 
 func updateMetrics(interval time.Duration) {
 	for {
 		authCfg := auth.AuthConfig{
-			Region:        s3Region,
-			Endpoint:      s3Endpoint,
-			AccessKey:     s3AccessKey,
-			SecretKey:     s3SecretKey,
-			SkipTLSVerify: s3SkipTLSVerify,
+			Method:          s3AuthMethod,
+			Region:          s3Region,
+			Endpoint:        s3Endpoint,
+			AccessKey:       s3AccessKey,
+			SecretKey:       s3SecretKey,
+			RoleARN:         s3RoleARN,
+			RoleSessionName: s3RoleSessionName,
+			ExternalID:      s3ExternalID,
+			DurationSeconds: s3DurationSeconds,
+			WebIdentity:     s3WebIdentity,
+			SkipTLSVerify:   s3SkipTLSVerify,
 		}
 
 		auth.DetectAuthMethod(&authCfg)
@@ -143,22 +286,29 @@ func updateMetrics(interval time.Duration) {
 			Region:         s3Region,
 			ForcePathStyle: s3ForcePathStyle,
 			AWSConfig:      &awsCfg,
+			Discovery: controllers.BucketDiscovery{
+				IncludeRegex: s3BucketIncludeRgx,
+				ExcludeRegex: s3BucketExcludeRgx,
+				DenyNames:    s3BucketDenyNames,
+				TagSelector:  s3BucketTagSelector,
+				ObjectPrefix: s3ObjectPrefix,
+			},
+			Posture: controllers.PostureConfig{
+				CollectVersioning:         s3CollectVersioning,
+				CollectEncryption:         s3CollectEncryption,
+				CollectPublicAccessBlock:  s3CollectPublicBlock,
+				CollectReplication:        s3CollectReplication,
+				CollectLifecycleRules:     s3CollectLifecycle,
+				CollectObjectLock:         s3CollectObjectLock,
+				CollectIntelligentTiering: s3CollectIntelTiering,
+			},
+			Listing: controllers.ListingConfig{
+				Workers:      s3ListWorkers,
+				PrefixShards: splitNonEmpty(s3ListPrefixShards),
+			},
 		}
 
-		metrics, err := controllers.S3UsageInfo(s3Conn, s3BucketNames)
-
-		metricsMutex.Lock()
-		if !reflect.DeepEqual(cachedMetrics, metrics) {
-			cachedMetrics = metrics
-		}
-		cachedError = err
-		metricsMutex.Unlock()
-
-		if err != nil {
-			log.Errorf("Failed to update S3 metrics: %v", err)
-		} else {
-			log.Debugf("Updated S3 metrics: %+v", metrics)
-		}
+		s3Collector.UpdateMetrics(s3Conn, s3BucketNames)
 
 		log.Debugf("Waiting for %v before updating metrics", interval)
 		time.Sleep(interval)
@@ -189,31 +339,75 @@ func main() {
 	}
 	log.SetLevel(level)
 
+	ageBuckets, ageOK := parseBucketBoundaries(s3ObjectAgeBuckets)
+	sizeBuckets, sizeOK := parseBucketBoundaries(s3ObjectSizeBuckets)
+	if ageOK || sizeOK {
+		if !ageOK {
+			ageBuckets = controllers.DefaultObjectAgeBuckets
+		}
+		if !sizeOK {
+			sizeBuckets = controllers.DefaultObjectSizeBuckets
+		}
+		controllers.SetHistogramBuckets(ageBuckets, sizeBuckets)
+	}
+
+	if s3BucketTagLabels != "" {
+		controllers.SetBucketTagLabels(strings.Split(s3BucketTagLabels, ","), s3AttachBucketTags)
+	}
+
+	if s3CollectClassBreakdown {
+		controllers.SetStorageClassBreakdown(true)
+	}
+
+	if s3InventoryMode {
+		configureInventoryMode()
+	}
+
+	if s3EventsSQSURL != "" {
+		configureEventsMode()
+	}
+
 	interval, err := time.ParseDuration(scrapeInterval)
 	if err != nil {
 		log.Fatalf("Invalid scrape interval: %s", scrapeInterval)
 	}
 
-	go updateMetrics(interval)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", healthHandler)
+
+	if configFile != "" {
+		targets, err := config.LoadTargetsConfig(configFile)
+		if err != nil {
+			log.Fatalf("Failed to load %s: %v", configFile, err)
+		}
+		log.Infof("Loaded %d target(s) from %s", len(targets), configFile)
+		serveMultiTarget(mux, targets, interval)
+	} else {
+		if _, err := storage.ParseBackend(storageBackend); err != nil {
+			log.Fatalf("Invalid storage_backend: %v", err)
+		}
 
-	prometheus.MustRegister(S3Collector{})
+		s3Collector = controllers.NewS3Collector(s3Endpoint, s3Region)
+		go updateMetrics(interval)
+		prometheus.MustRegister(s3Collector)
+		mux.Handle("/metrics", promhttp.Handler())
 
-	http.Handle("/metrics", promhttp.Handler())
-	http.HandleFunc("/health", healthHandler)
+		if s3BucketNames != "" {
+			log.Infof("Monitoring buckets: %s in %s region", s3BucketNames, s3Region)
+		} else {
+			log.Infof("Monitoring all buckets in %s region", s3Region)
+		}
+	}
 
 	srv := &http.Server{
 		Addr:         listenPort,
+		Handler:      mux,
 		ReadTimeout:  35 * time.Second,
 		WriteTimeout: 35 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
 
 	log.Infof("Starting server on %s", listenPort)
-	if s3BucketNames != "" {
-		log.Infof("Monitoring buckets: %s in %s region", s3BucketNames, s3Region)
-	} else {
-		log.Infof("Monitoring all buckets in %s region", s3Region)
-	}
 
 	if err := srv.ListenAndServe(); err != nil {
 		log.Fatalf("Server failed to start: %v", err)
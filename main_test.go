@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"net/http/httptest"
@@ -12,26 +13,91 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
 	"github.com/prometheus/client_golang/prometheus"
 	io_prometheus_client "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tropnikovvl/s3-bucket-exporter/auth"
 	"github.com/tropnikovvl/s3-bucket-exporter/controllers"
 )
 
-// Mock implementation for S3 client interface
-type mockS3Client struct {
-	controllers.S3ClientInterface
-	listBucketsFunc   func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
-	listObjectsV2Func func(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+// newFakeS3Server stands up an in-process, wire-compatible S3 server backed
+// by gofakes3/s3mem so tests exercise real pagination and signing instead of
+// a hand-rolled interface mock.
+func newFakeS3Server(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(gofakes3.New(s3mem.New()).Server())
+	t.Cleanup(server.Close)
+
+	return server
 }
 
-func (m *mockS3Client) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
-	return m.listBucketsFunc(ctx, params, optFns...)
+// seedFakeBucket creates bucket on the fake server, failing the test if it
+// already exists, and uploads a single object of the given size and storage
+// class.
+func seedFakeBucket(t *testing.T, server *httptest.Server, bucket, key string, size int, storageClass types.StorageClass) {
+	t.Helper()
+
+	authCfg := auth.AuthConfig{
+		Method:    auth.AuthMethodKeys,
+		Region:    "us-east-1",
+		Endpoint:  server.URL,
+		AccessKey: "test-access-key",
+		SecretKey: "test-secret-key",
+	}
+
+	awsCfg, err := auth.NewAWSAuth(authCfg).GetConfig(context.Background())
+	require.NoError(t, err)
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+
+	ctx := context.Background()
+	_, err = client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)})
+	require.NoError(t, err)
+
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		Body:         bytes.NewReader(bytes.Repeat([]byte{0}, size)),
+		StorageClass: storageClass,
+	})
+	require.NoError(t, err)
 }
 
-func (m *mockS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
-	return m.listObjectsV2Func(ctx, params, optFns...)
+// putFakeObject uploads raw bytes to bucket/key on the fake server, creating
+// the bucket first.
+func putFakeObject(t *testing.T, server *httptest.Server, bucket, key string, body []byte) {
+	t.Helper()
+
+	authCfg := auth.AuthConfig{
+		Method:    auth.AuthMethodKeys,
+		Region:    "us-east-1",
+		Endpoint:  server.URL,
+		AccessKey: "test-access-key",
+		SecretKey: "test-secret-key",
+	}
+
+	awsCfg, err := auth.NewAWSAuth(authCfg).GetConfig(context.Background())
+	require.NoError(t, err)
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+
+	ctx := context.Background()
+	_, _ = client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)})
+
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(body),
+	})
+	require.NoError(t, err)
 }
 
 func TestEnvString(t *testing.T) {
@@ -113,6 +179,20 @@ func TestEnvBool(t *testing.T) {
 	}
 }
 
+func TestParseBucketBoundaries(t *testing.T) {
+	buckets, ok := parseBucketBoundaries("")
+	assert.False(t, ok)
+	assert.Nil(t, buckets)
+
+	buckets, ok = parseBucketBoundaries("60,300,3600")
+	assert.True(t, ok)
+	assert.Equal(t, []float64{60, 300, 3600}, buckets)
+
+	buckets, ok = parseBucketBoundaries("60,not-a-number")
+	assert.False(t, ok)
+	assert.Nil(t, buckets)
+}
+
 func TestHealthHandler(t *testing.T) {
 	req, err := http.NewRequest("GET", "/health", nil)
 	assert.NoError(t, err)
@@ -167,9 +247,9 @@ func TestS3Collector(t *testing.T) {
 	s3Endpoint = "http://localhost"
 	s3Region = "us-east-1"
 
-	metricsMutex.Lock()
-	cachedMetrics = controllers.S3Summary{
-		S3Status: true,
+	collector := controllers.NewS3Collector(s3Endpoint, s3Region)
+	collector.Metrics = controllers.S3Summary{
+		EndpointStatus: true,
 		StorageClasses: map[string]controllers.StorageClassMetrics{
 			"STANDARD": {
 				Size:         1000.0,
@@ -179,7 +259,8 @@ func TestS3Collector(t *testing.T) {
 		TotalListDuration: 2 * time.Second,
 		S3Buckets: []controllers.Bucket{
 			{
-				BucketName: "test-bucket",
+				BucketName:   "test-bucket",
+				BucketRegion: "us-east-1",
 				StorageClasses: map[string]controllers.StorageClassMetrics{
 					"STANDARD": {
 						Size:         500.0,
@@ -190,34 +271,51 @@ func TestS3Collector(t *testing.T) {
 			},
 		},
 	}
-	cachedError = nil
-	metricsMutex.Unlock()
 
-	collector := S3Collector{}
+	// Posture metrics are gated on the collector's own (unexported) posture
+	// field, populated by UpdateMetrics from controllers.S3Conn.Posture - not
+	// by main.go's s3Collect* flags directly - so a collector built without
+	// going through UpdateMetrics (as here) never emits them regardless of
+	// those flags. TestUpdateMetrics below drives a collector through
+	// UpdateMetrics end to end, with every s3Collect* flag left off.
 	ch := make(chan prometheus.Metric)
 	done := make(chan bool)
 
 	var metrics []prometheus.Metric
 
 	go func() {
+		bucketLabels := map[string]string{"s3Target": "", "s3Endpoint": s3Endpoint, "s3Region": s3Region, "bucketName": "test-bucket", "bucketRegion": "us-east-1"}
+		withLabel := func(extra map[string]string) map[string]string {
+			merged := make(map[string]string, len(bucketLabels)+len(extra))
+			for k, v := range bucketLabels {
+				merged[k] = v
+			}
+			for k, v := range extra {
+				merged[k] = v
+			}
+			return merged
+		}
+
 		expectedExact := []struct {
 			name   string
 			labels map[string]string
 			value  float64
 		}{
-			{"s3_endpoint_up", map[string]string{"s3Endpoint": s3Endpoint, "s3Region": s3Region}, 1.0},
-			{"s3_total_size", map[string]string{"s3Endpoint": s3Endpoint, "s3Region": s3Region, "storageClass": "STANDARD"}, 1000.0},
-			{"s3_total_object_number", map[string]string{"s3Endpoint": s3Endpoint, "s3Region": s3Region, "storageClass": "STANDARD"}, 50.0},
-			{"s3_bucket_size", map[string]string{"s3Endpoint": s3Endpoint, "s3Region": s3Region, "bucketName": "test-bucket", "storageClass": "STANDARD"}, 500.0},
-			{"s3_bucket_object_number", map[string]string{"s3Endpoint": s3Endpoint, "s3Region": s3Region, "bucketName": "test-bucket", "storageClass": "STANDARD"}, 25.0},
+			{"s3_endpoint_up", map[string]string{"s3Target": "", "s3Endpoint": s3Endpoint, "s3Region": s3Region}, 1.0},
+			{"s3_total_size", map[string]string{"s3Target": "", "s3Endpoint": s3Endpoint, "s3Region": s3Region, "storageClass": "STANDARD"}, 1000.0},
+			{"s3_total_object_number", map[string]string{"s3Target": "", "s3Endpoint": s3Endpoint, "s3Region": s3Region, "storageClass": "STANDARD"}, 50.0},
+			{"s3_bucket_size", withLabel(map[string]string{"storageClass": "STANDARD"}), 500.0},
+			{"s3_bucket_object_number", withLabel(map[string]string{"storageClass": "STANDARD"}), 25.0},
+			{"s3_list_api_calls", bucketLabels, 0.0},
+			{"s3_bucket_info", bucketLabels, 1.0},
 		}
 
 		expectedDuration := []struct {
 			name   string
 			labels map[string]string
 		}{
-			{"s3_list_total_duration_seconds", map[string]string{"s3Endpoint": s3Endpoint, "s3Region": s3Region}},
-			{"s3_list_duration_seconds", map[string]string{"s3Endpoint": s3Endpoint, "s3Region": s3Region, "bucketName": "test-bucket"}},
+			{"s3_list_total_duration_seconds", map[string]string{"s3Target": "", "s3Endpoint": s3Endpoint, "s3Region": s3Region}},
+			{"s3_list_duration_seconds", bucketLabels},
 		}
 
 		var matchedExactCount int
@@ -257,37 +355,22 @@ func TestS3Collector(t *testing.T) {
 }
 
 func TestUpdateMetrics(t *testing.T) {
-	mockClient := &mockS3Client{
-		listBucketsFunc: func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
-			return &s3.ListBucketsOutput{
-				Buckets: []types.Bucket{
-					{Name: aws.String("test-bucket")},
-				},
-			}, nil
-		},
-		listObjectsV2Func: func(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
-			return &s3.ListObjectsV2Output{
-				Contents: []types.Object{
-					{
-						Key:          aws.String("test-object"),
-						Size:         aws.Int64(1024),
-						StorageClass: types.ObjectStorageClass("STANDARD"),
-					},
-				},
-				IsTruncated: aws.Bool(false),
-			}, nil
-		},
-	}
+	server := newFakeS3Server(t)
+	seedFakeBucket(t, server, "test-bucket", "test-object", 1024, types.StorageClassStandard)
 
-	s3Endpoint = "http://localhost"
-	s3AccessKey = "test"
-	s3SecretKey = "test"
+	s3Endpoint = server.URL
+	s3AccessKey = "test-access-key"
+	s3SecretKey = "test-secret-key"
 	s3Region = "us-east-1"
 	s3BucketNames = "test-bucket"
+	s3ForcePathStyle = true
+	defer func() { s3ForcePathStyle = false }()
 
-	controllers.SetS3Client(mockClient)
+	controllers.ResetS3Client()
 	defer controllers.ResetS3Client()
 
+	s3Collector = controllers.NewS3Collector(s3Endpoint, s3Region)
+
 	interval := 100 * time.Millisecond
 	done := make(chan bool)
 
@@ -302,21 +385,19 @@ func TestUpdateMetrics(t *testing.T) {
 
 	<-done
 
-	metricsMutex.RLock()
-	defer metricsMutex.RUnlock()
-
-	assert.NoError(t, cachedError, "Expected no error with mock client")
-	assert.Equal(t, true, cachedMetrics.S3Status, "S3Status should be true")
-	metrics := cachedMetrics.StorageClasses["STANDARD"]
+	assert.NoError(t, s3Collector.Err, "Expected no error against the fake S3 server")
+	assert.Equal(t, true, s3Collector.Metrics.EndpointStatus, "S3Status should be true")
+	metrics := s3Collector.Metrics.StorageClasses["STANDARD"]
 	assert.Equal(t, 1024.0, metrics.Size, "Total size should match")
 	assert.Equal(t, 1.0, metrics.ObjectNumber, "Total object number should match")
-	require.Len(t, cachedMetrics.S3Buckets, 1, "Should have exactly one bucket")
+	require.Len(t, s3Collector.Metrics.S3Buckets, 1, "Should have exactly one bucket")
 
-	bucket := cachedMetrics.S3Buckets[0]
+	bucket := s3Collector.Metrics.S3Buckets[0]
 	assert.Equal(t, "test-bucket", bucket.BucketName, "BucketName should match")
+	assert.Equal(t, "us-east-1", bucket.BucketRegion, "BucketRegion should be discovered and carried onto the single-target collector's metrics")
 	bucketMetrics := bucket.StorageClasses["STANDARD"]
 	assert.Equal(t, 1024.0, bucketMetrics.Size, "Bucket size should match")
 	assert.Equal(t, 1.0, bucketMetrics.ObjectNumber, "Bucket object number should match")
-	assert.Greater(t, cachedMetrics.TotalListDuration, time.Duration(0), "TotalListDuration should be positive")
+	assert.Greater(t, s3Collector.Metrics.TotalListDuration, time.Duration(0), "TotalListDuration should be positive")
 	assert.Greater(t, bucket.ListDuration, time.Duration(0), "Bucket ListDuration should be positive")
 }
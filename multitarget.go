@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"github.com/tropnikovvl/s3-bucket-exporter/auth"
+	"github.com/tropnikovvl/s3-bucket-exporter/config"
+	"github.com/tropnikovvl/s3-bucket-exporter/controllers"
+	"github.com/tropnikovvl/s3-bucket-exporter/storage"
+)
+
+// targetRunner bundles everything needed to scrape one configured target on
+// its own schedule and serve it from its own prometheus.Registry.
+type targetRunner struct {
+	target    config.Target
+	collector *controllers.S3Collector
+	registry  *prometheus.Registry
+}
+
+// newTargetRunner builds a runner for a single target and registers its
+// collector against a private registry, so one target's S3Collector metrics
+// never appear in another target's /metrics response. The API, histogram,
+// inventory and auth metric vectors registered alongside it are shared by
+// every target, so each target's response carries all targets' series of
+// those, told apart by their s3Target label.
+func newTargetRunner(t config.Target) *targetRunner {
+	collector := controllers.NewS3CollectorForTarget(t.Name, t.Endpoint, t.Region)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	controllers.RegisterAPIMetrics(registry)
+	controllers.RegisterHistogramMetrics(registry)
+	controllers.RegisterInventoryMetrics(registry)
+	auth.RegisterMetrics(registry)
+
+	configureTargetInventory(t)
+	configureTargetEvents(t)
+	configureTargetCredentialProfiles(context.Background(), t)
+
+	return &targetRunner{target: t, collector: collector, registry: registry}
+}
+
+// configureTargetCredentialProfiles builds and registers an S3 client for
+// each of t's credential profiles, so fetchBucketData can pick the right
+// client for buckets annotated with "@profile" in bucket_names. A profile
+// defaults to t's own Endpoint/Region but may override either, letting a
+// profile reach a bucket on a different S3-compatible endpoint or account
+// entirely. Each profile's AssumeRole/web-identity credentials are wrapped
+// in the AWS SDK's own refreshing credential cache, so the client built
+// here stays valid without the exporter needing to rebuild it before
+// expiry.
+func configureTargetCredentialProfiles(ctx context.Context, t config.Target) {
+	for name, profile := range t.CredentialProfiles {
+		region := profile.Region
+		if region == "" {
+			region = t.Region
+		}
+
+		endpoint := profile.Endpoint
+		if endpoint == "" {
+			endpoint = t.Endpoint
+		}
+
+		authCfg := auth.AuthConfig{
+			Target:          t.Name,
+			Method:          profile.Method,
+			Region:          region,
+			Endpoint:        endpoint,
+			AccessKey:       profile.AccessKey,
+			SecretKey:       profile.SecretKey,
+			RoleARN:         profile.RoleARN,
+			RoleARNChain:    profile.RoleARNChain,
+			RoleSessionName: profile.RoleSessionName,
+			ExternalID:      profile.ExternalID,
+			DurationSeconds: profile.DurationSeconds,
+			WebIdentity:     profile.WebIdentity,
+			SkipTLSVerify:   profile.SkipTLSVerify,
+		}
+		auth.DetectAuthMethod(&authCfg)
+
+		awsCfg, err := auth.NewAWSAuth(authCfg).GetConfig(ctx)
+		if err != nil {
+			log.Errorf("Target %s: failed to configure credential profile %q: %v", t.Name, name, err)
+			continue
+		}
+
+		client, err := controllers.GetS3Client(controllers.S3Conn{
+			Endpoint:       endpoint,
+			Region:         region,
+			ForcePathStyle: t.ForcePathStyle,
+			AWSConfig:      &awsCfg,
+		}, t.Name)
+		if err != nil {
+			log.Errorf("Target %s: failed to build client for credential profile %q: %v", t.Name, name, err)
+			continue
+		}
+
+		controllers.SetCredentialProfileClient(t.Name, name, client, &awsCfg, t.ForcePathStyle)
+	}
+}
+
+// configureTargetInventory registers t's buckets against the S3 Inventory
+// data source instead of a live ListObjectsV2 walk when t.Mode is
+// "inventory". Inventory mode only applies to buckets named explicitly in
+// bucket_names, since the manifest path is keyed by source bucket name.
+func configureTargetInventory(t config.Target) {
+	if t.Mode != "inventory" {
+		return
+	}
+
+	if t.BucketNames == "" {
+		log.Errorf("Target %s: mode is \"inventory\" but bucket_names is empty, inventory mode requires explicitly named buckets", t.Name)
+		return
+	}
+
+	maxAge, err := time.ParseDuration(t.Inventory.MaxAge)
+	if err != nil && t.Inventory.MaxAge != "" {
+		log.Errorf("Target %s: invalid inventory.max_age %q, ignoring freshness window", t.Name, t.Inventory.MaxAge)
+	}
+
+	controllers.ConfigureInventoryForBuckets(t.Name, t.BucketNames, controllers.InventoryConfig{
+		Bucket: t.Inventory.Bucket,
+		Prefix: t.Inventory.Prefix,
+		Format: "CSV",
+		MaxAge: maxAge,
+	})
+}
+
+// configureTargetEvents starts an SQS-backed event listener for t's buckets
+// when t.Mode is "events", maintaining in-memory counters reconciled against
+// a full scan on t.Events.FullReconcileInterval. Like inventory mode, it only
+// applies to buckets named explicitly in bucket_names.
+func configureTargetEvents(t config.Target) {
+	if t.Mode != "events" {
+		return
+	}
+
+	if t.BucketNames == "" {
+		log.Errorf("Target %s: mode is \"events\" but bucket_names is empty, events mode requires explicitly named buckets", t.Name)
+		return
+	}
+
+	region := t.Events.Region
+	if region == "" {
+		region = t.Region
+	}
+
+	reconcileInterval, err := time.ParseDuration(t.Events.FullReconcileInterval)
+	if err != nil {
+		reconcileInterval = 24 * time.Hour
+		if t.Events.FullReconcileInterval != "" {
+			log.Errorf("Target %s: invalid events.full_reconcile_interval %q, using default %v", t.Name, t.Events.FullReconcileInterval, reconcileInterval)
+		}
+	}
+
+	authCfg := auth.AuthConfig{
+		Target:          t.Name,
+		Method:          t.Auth.Method,
+		Region:          region,
+		Endpoint:        t.Endpoint,
+		AccessKey:       t.Auth.AccessKey,
+		SecretKey:       t.Auth.SecretKey,
+		RoleARN:         t.Auth.RoleARN,
+		RoleARNChain:    t.Auth.RoleARNChain,
+		RoleSessionName: t.Auth.RoleSessionName,
+		ExternalID:      t.Auth.ExternalID,
+		DurationSeconds: t.Auth.DurationSeconds,
+		WebIdentity:     t.Auth.WebIdentity,
+		SkipTLSVerify:   t.Auth.SkipTLSVerify,
+	}
+	auth.DetectAuthMethod(&authCfg)
+
+	awsCfg, err := auth.NewAWSAuth(authCfg).GetConfig(context.Background())
+	if err != nil {
+		log.Errorf("Target %s: failed to configure authentication for event-driven mode: %v", t.Name, err)
+		return
+	}
+
+	controllers.ConfigureEventsForBuckets(context.Background(), t.Name, t.BucketNames, controllers.EventsConfig{
+		SQSURL:                t.Events.SQSURL,
+		AWSConfig:             &awsCfg,
+		FullReconcileInterval: reconcileInterval,
+	})
+}
+
+// run periodically refreshes the target's cached metrics until ctx is done.
+func (r *targetRunner) run(ctx context.Context, defaultInterval time.Duration) {
+	interval := defaultInterval
+	if r.target.ScrapeInterval != "" {
+		if parsed, err := time.ParseDuration(r.target.ScrapeInterval); err == nil {
+			interval = parsed
+		} else {
+			log.Errorf("Target %s: invalid scrape_interval %q, using default %v", r.target.Name, r.target.ScrapeInterval, defaultInterval)
+		}
+	}
+
+	for {
+		if _, err := storage.ParseBackend(r.target.Backend); err != nil {
+			log.Errorf("Target %s: %v", r.target.Name, err)
+		} else {
+			r.scrapeS3(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// scrapeS3 authenticates against r.target's S3-compatible endpoint and
+// refreshes the collector's cached metrics for one scrape cycle.
+func (r *targetRunner) scrapeS3(ctx context.Context) {
+	authCfg := auth.AuthConfig{
+		Target:          r.target.Name,
+		Method:          r.target.Auth.Method,
+		Region:          r.target.Region,
+		Endpoint:        r.target.Endpoint,
+		AccessKey:       r.target.Auth.AccessKey,
+		SecretKey:       r.target.Auth.SecretKey,
+		RoleARN:         r.target.Auth.RoleARN,
+		RoleARNChain:    r.target.Auth.RoleARNChain,
+		RoleSessionName: r.target.Auth.RoleSessionName,
+		ExternalID:      r.target.Auth.ExternalID,
+		DurationSeconds: r.target.Auth.DurationSeconds,
+		WebIdentity:     r.target.Auth.WebIdentity,
+		SkipTLSVerify:   r.target.Auth.SkipTLSVerify,
+	}
+	auth.DetectAuthMethod(&authCfg)
+
+	awsCfg, err := auth.NewAWSAuth(authCfg).GetConfig(ctx)
+	if err != nil {
+		log.Errorf("Target %s: failed to configure authentication: %v", r.target.Name, err)
+		return
+	}
+
+	s3Conn := controllers.S3Conn{
+		Endpoint:       r.target.Endpoint,
+		Region:         r.target.Region,
+		ForcePathStyle: r.target.ForcePathStyle,
+		AWSConfig:      &awsCfg,
+		Discovery: controllers.BucketDiscovery{
+			IncludeRegex: r.target.BucketIncludeRegex,
+			ExcludeRegex: r.target.BucketExcludeRegex,
+			DenyNames:    r.target.BucketDenyNames,
+			TagSelector:  r.target.BucketTagSelector,
+			ObjectPrefix: r.target.ObjectPrefix,
+		},
+		Posture: controllers.PostureConfig{
+			CollectVersioning:         r.target.Posture.CollectVersioning,
+			CollectEncryption:         r.target.Posture.CollectEncryption,
+			CollectPublicAccessBlock:  r.target.Posture.CollectPublicAccessBlock,
+			CollectReplication:        r.target.Posture.CollectReplication,
+			CollectLifecycleRules:     r.target.Posture.CollectLifecycleRules,
+			CollectObjectLock:         r.target.Posture.CollectObjectLock,
+			CollectIntelligentTiering: r.target.Posture.CollectIntelligentTiering,
+		},
+		Listing: controllers.ListingConfig{
+			Workers:      r.target.Listing.Workers,
+			PrefixShards: r.target.Listing.PrefixShards,
+		},
+	}
+	r.collector.UpdateMetrics(s3Conn, r.target.BucketNames)
+}
+
+// serveMultiTarget starts a scrape loop per configured target and exposes
+// them all on /metrics, selected via the ?target=<name> query parameter, in
+// addition to distinct paths under /metrics/<name> for scrapers that would
+// rather avoid query strings.
+func serveMultiTarget(mux *http.ServeMux, targets []config.Target, defaultInterval time.Duration) {
+	runners := make(map[string]*targetRunner, len(targets))
+
+	for _, t := range targets {
+		r := newTargetRunner(t)
+		runners[t.Name] = r
+		go r.run(context.Background(), defaultInterval)
+
+		mux.Handle("/metrics/"+t.Name, promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{}))
+		log.Infof("Registered target %s (%s, region %s) at /metrics/%s", t.Name, t.Endpoint, t.Region, t.Name)
+	}
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, req *http.Request) {
+		name := req.URL.Query().Get("target")
+		if name == "" {
+			http.Error(w, "missing required 'target' query parameter", http.StatusBadRequest)
+			return
+		}
+
+		r, ok := runners[name]
+		if !ok {
+			http.Error(w, "unknown target: "+name, http.StatusNotFound)
+			return
+		}
+
+		promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{}).ServeHTTP(w, req)
+	})
+}
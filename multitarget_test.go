@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tropnikovvl/s3-bucket-exporter/config"
+	"github.com/tropnikovvl/s3-bucket-exporter/controllers"
+)
+
+func TestNewTargetRunner_RegistersCollectorAgainstItsOwnRegistry(t *testing.T) {
+	target := config.Target{Name: "ceph", Endpoint: "https://ceph.example.com", Region: "us-east-1"}
+
+	runner := newTargetRunner(target)
+
+	assert.Equal(t, target, runner.target)
+	metricFamilies, err := runner.registry.Gather()
+	require.NoError(t, err)
+
+	var found bool
+	for _, mf := range metricFamilies {
+		if mf.GetName() == "s3_endpoint_up" {
+			found = true
+		}
+	}
+	assert.True(t, found, "target's registry should expose s3_endpoint_up")
+}
+
+func TestTargetRunner_Run_UpdatesMetricsAgainstFakeServer(t *testing.T) {
+	server := newFakeS3Server(t)
+	seedFakeBucket(t, server, "target-bucket", "obj", 512, types.StorageClassStandard)
+
+	target := config.Target{
+		Name:           "fake",
+		Endpoint:       server.URL,
+		Region:         "us-east-1",
+		BucketNames:    "target-bucket",
+		ForcePathStyle: true,
+		ScrapeInterval: "50ms",
+		Auth: config.TargetAuth{
+			Method:    "keys",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		},
+	}
+
+	runner := newTargetRunner(target)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.run(ctx, time.Minute)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return endpointUpValue(t, runner) == 1
+	}, 2*time.Second, 10*time.Millisecond, "collector should report a healthy endpoint once scraped")
+
+	cancel()
+	<-done
+}
+
+// endpointUpValue scrapes runner's registry and returns the s3_endpoint_up
+// gauge value, using the same public Collector interface Prometheus itself
+// relies on rather than reaching past the collector's own mutex.
+func endpointUpValue(t *testing.T, runner *targetRunner) float64 {
+	t.Helper()
+
+	metricFamilies, err := runner.registry.Gather()
+	require.NoError(t, err)
+
+	for _, mf := range metricFamilies {
+		if mf.GetName() == "s3_endpoint_up" {
+			return mf.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestTargetRunner_Run_UsesInventoryModeInsteadOfListing(t *testing.T) {
+	server := newFakeS3Server(t)
+	seedFakeBucket(t, server, "cold-archive", "obj", 999, types.StorageClassStandard)
+
+	manifestPrefix := "reports/cold-archive/config-1/2024-01-02T00-00Z"
+	var gz bytes.Buffer
+	gzw := gzip.NewWriter(&gz)
+	_, err := gzw.Write([]byte("cold-archive,key1,5000,2024-01-01T00:00:00.000Z,STANDARD\n"))
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+
+	putFakeObject(t, server, "inventory-reports", manifestPrefix+"/data-1.csv.gz", gz.Bytes())
+	putFakeObject(t, server, "inventory-reports", manifestPrefix+"/manifest.json", []byte(`{
+		"fileFormat": "CSV",
+		"fileSchema": "Bucket, Key, Size, LastModifiedDate, StorageClass",
+		"files": [{"key": "`+manifestPrefix+`/data-1.csv.gz"}]
+	}`))
+
+	controllers.ResetInventoryConfigs()
+	t.Cleanup(controllers.ResetInventoryConfigs)
+
+	target := config.Target{
+		Name:           "cold-archive",
+		Endpoint:       server.URL,
+		Region:         "us-east-1",
+		BucketNames:    "cold-archive",
+		ForcePathStyle: true,
+		ScrapeInterval: "50ms",
+		Mode:           "inventory",
+		Inventory: config.TargetInventory{
+			Bucket: "inventory-reports",
+			Prefix: "reports",
+		},
+		Auth: config.TargetAuth{
+			Method:    "keys",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		},
+	}
+
+	runner := newTargetRunner(target)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.run(ctx, time.Minute)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return bucketSizeValue(t, runner) == 5000
+	}, 2*time.Second, 10*time.Millisecond, "collector should report the inventory-derived size, not the live object's 999 bytes")
+
+	cancel()
+	<-done
+}
+
+// bucketSizeValueFor scrapes runner's registry and returns the s3_bucket_size
+// gauge value for the given bucketName label.
+func bucketSizeValueFor(t *testing.T, runner *targetRunner, bucketName string) float64 {
+	t.Helper()
+
+	metricFamilies, err := runner.registry.Gather()
+	require.NoError(t, err)
+
+	for _, mf := range metricFamilies {
+		if mf.GetName() != "s3_bucket_size" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "bucketName" && label.GetValue() == bucketName {
+					return m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// bucketSizeValue scrapes runner's registry and returns the s3_bucket_size
+// gauge value.
+func bucketSizeValue(t *testing.T, runner *targetRunner) float64 {
+	t.Helper()
+
+	metricFamilies, err := runner.registry.Gather()
+	require.NoError(t, err)
+
+	for _, mf := range metricFamilies {
+		if mf.GetName() == "s3_bucket_size" && len(mf.GetMetric()) > 0 {
+			return mf.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestTargetRunner_Run_SkipsScrapeForUnimplementedBackend(t *testing.T) {
+	target := config.Target{
+		Name:    "gcs-archive",
+		Backend: "GCS",
+	}
+
+	runner := newTargetRunner(target)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, float64(0), endpointUpValue(t, runner), "unimplemented backend should never update metrics")
+
+	cancel()
+	<-done
+}
+
+func TestTargetRunner_Run_ScrapesCredentialProfileBucketFromItsOwnAccount(t *testing.T) {
+	defer controllers.ResetCredentialProfileClients()
+
+	defaultServer := newFakeS3Server(t)
+	seedFakeBucket(t, defaultServer, "own-bucket", "obj", 512, types.StorageClassStandard)
+
+	profileServer := newFakeS3Server(t)
+	seedFakeBucket(t, profileServer, "tenant-bucket", "obj", 1024, types.StorageClassStandard)
+
+	target := config.Target{
+		Name:           "shared",
+		Endpoint:       defaultServer.URL,
+		Region:         "us-east-1",
+		BucketNames:    "own-bucket,tenant-bucket@tenant-a",
+		ForcePathStyle: true,
+		ScrapeInterval: "50ms",
+		Auth: config.TargetAuth{
+			Method:    "keys",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		},
+		CredentialProfiles: map[string]config.CredentialProfile{
+			"tenant-a": {
+				TargetAuth: config.TargetAuth{
+					Method:    "keys",
+					AccessKey: "test-access-key",
+					SecretKey: "test-secret-key",
+				},
+				// Overrides the target's own endpoint, so the profile's
+				// client reaches tenant-bucket on a genuinely distinct
+				// account/endpoint rather than the target's default one.
+				Endpoint: profileServer.URL,
+			},
+		},
+	}
+
+	runner := newTargetRunner(target)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.run(ctx, time.Minute)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return bucketSizeValueFor(t, runner, "tenant-bucket") == 1024
+	}, 2*time.Second, 10*time.Millisecond, "tenant-bucket should be scraped via its credential profile client")
+
+	assert.Equal(t, float64(512), bucketSizeValueFor(t, runner, "own-bucket"), "own-bucket should still use the target's default credentials")
+
+	cancel()
+	<-done
+}
+
+func TestTargetRunner_Run_DiscoversBucketsWithIncludeRegex(t *testing.T) {
+	server := newFakeS3Server(t)
+	seedFakeBucket(t, server, "prod-logs", "obj", 512, types.StorageClassStandard)
+	seedFakeBucket(t, server, "dev-logs", "obj", 256, types.StorageClassStandard)
+
+	target := config.Target{
+		Name:               "discovered",
+		Endpoint:           server.URL,
+		Region:             "us-east-1",
+		ForcePathStyle:     true,
+		ScrapeInterval:     "50ms",
+		BucketIncludeRegex: "^prod-",
+		Auth: config.TargetAuth{
+			Method:    "keys",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		},
+	}
+
+	runner := newTargetRunner(target)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.run(ctx, time.Minute)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return bucketSizeValueFor(t, runner, "prod-logs") == 512
+	}, 2*time.Second, 10*time.Millisecond, "prod-logs should be discovered and scraped")
+
+	assert.Equal(t, float64(0), bucketSizeValueFor(t, runner, "dev-logs"), "dev-logs should be filtered out by the include regex")
+
+	cancel()
+	<-done
+}
+
+func TestServeMultiTarget_RoutesByPathAndQueryParam(t *testing.T) {
+	targets := []config.Target{
+		{Name: "a", Endpoint: "https://a.example.com", Region: "us-east-1"},
+		{Name: "b", Endpoint: "https://b.example.com", Region: "us-east-1"},
+	}
+
+	mux := http.NewServeMux()
+	serveMultiTarget(mux, targets, time.Minute)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/metrics/a")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/metrics?target=b")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/metrics?target=unknown")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,35 @@
+// Package storage identifies which object storage provider a target talks
+// to. Only S3 (and S3-compatible endpoints) is currently supported. GCS,
+// Azure Blob and Swift support (via a thanos-io/objstore-style interface
+// layered under controllers.S3ClientInterface) was requested but is not
+// implemented in this tree - ParseBackend rejects those values rather than
+// silently falling back to S3, so callers asking for an unsupported backend
+// fail loudly instead of getting S3 metrics under a different label.
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Backend identifies which object storage provider a target talks to.
+type Backend string
+
+const (
+	BackendS3 Backend = "S3"
+)
+
+// ParseBackend normalizes and validates a backend name from config/flags,
+// defaulting to BackendS3 when raw is empty.
+func ParseBackend(raw string) (Backend, error) {
+	if raw == "" {
+		return BackendS3, nil
+	}
+
+	switch b := Backend(strings.ToUpper(raw)); b {
+	case BackendS3:
+		return b, nil
+	default:
+		return "", fmt.Errorf("unknown storage_backend %q, expected S3", raw)
+	}
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBackend(t *testing.T) {
+	b, err := ParseBackend("")
+	require.NoError(t, err)
+	assert.Equal(t, BackendS3, b)
+
+	b, err = ParseBackend("s3")
+	require.NoError(t, err)
+	assert.Equal(t, BackendS3, b)
+
+	_, err = ParseBackend("gcs")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown storage_backend")
+}